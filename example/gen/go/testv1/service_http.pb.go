@@ -1,72 +1,761 @@
-// Code generated by github.com/mohuishou/protoc-gen-go-gin. DO NOT EDIT.
+// Code generated by protoc-gen-http-go. DO NOT EDIT.
 
 package testv1
 
 import (
 	context "context"
-	json "encoding/json"
+	_ "embed"
 	errors "errors"
-	io "io"
+	runtime "github.com/peterchanxyz/protoc-gen-http-go/runtime"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
+	grpc "google.golang.org/grpc"
+	proto "google.golang.org/protobuf/proto"
+	iter "iter"
 	http "net/http"
+	time "time"
 )
 
+//go:embed service.openapi.json
+var file_testv1_service_proto_openapi []byte
+
 // TestServiceServer is the server API for TestService service.
 type TestServiceServer interface {
 	GameLaunch(context.Context, *GameLaunchInput) (*GameLaunchResult, error)
+	CreateGame(context.Context, *CreateGameInput) (*Game, error)
+	ListGames(context.Context, *ListGamesInput) (*ListGamesResult, error)
+	GetGameIcon(context.Context, *GetGameIconInput) (*httpbody.HttpBody, error)
+	UploadGameIcon(context.Context, *UploadGameIconInput) (*Game, error)
+	WatchGames(*WatchGamesInput, grpc.ServerStreamingServer[Game]) error
 }
 
-func writeErr(w http.ResponseWriter, err error) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusBadRequest)
-	w.Write([]byte(`{"error":` + err.Error() + `}`))
-}
-
-func writeRsp(w http.ResponseWriter, resp any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	respba, err := json.Marshal(resp)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	w.Write(respba)
-}
-func RegisterHttpServer(srv any, impl TestServiceServer) (err error) {
+func RegisterHttpServer(srv any, impl TestServiceServer, opts ...runtime.ServerOption) (err error) {
 	mux, ok := srv.(interface{ Handle(string, http.Handler) })
 	if !ok {
 		err = errors.New("srv must implement HttpServerMux")
 		return
 	}
-	mux.Handle(GameLaunchHandler(impl))
+	o := runtime.NewServerOptions(opts...)
+	mux.Handle(o.CORS(GameLaunchHandler(impl, opts...)))
+	mux.Handle(o.CORS(CreateGameHandler(impl, opts...)))
+	mux.Handle(o.CORS(ListGamesHandler(impl, opts...)))
+	mux.Handle(o.CORS(GetGameIconHandler(impl, opts...)))
+	mux.Handle(o.CORS(UploadGameIconHandler(impl, opts...)))
+	{
+		pattern, connect := GameLaunchConnectHandler(impl, opts...)
+		_, grpcWeb := GameLaunchGRPCWebHandler(impl, opts...)
+		mux.Handle(o.CORS(pattern, runtime.GRPCWebOr(grpcWeb, connect)))
+	}
+	mux.Handle(o.CORS(GameLaunchTwirpHandler(impl, opts...)))
+	{
+		pattern, connect := CreateGameConnectHandler(impl, opts...)
+		_, grpcWeb := CreateGameGRPCWebHandler(impl, opts...)
+		mux.Handle(o.CORS(pattern, runtime.GRPCWebOr(grpcWeb, connect)))
+	}
+	mux.Handle(o.CORS(CreateGameTwirpHandler(impl, opts...)))
+	{
+		pattern, connect := ListGamesConnectHandler(impl, opts...)
+		_, grpcWeb := ListGamesGRPCWebHandler(impl, opts...)
+		mux.Handle(o.CORS(pattern, runtime.GRPCWebOr(grpcWeb, connect)))
+	}
+	mux.Handle(o.CORS(ListGamesTwirpHandler(impl, opts...)))
+	{
+		pattern, connect := GetGameIconConnectHandler(impl, opts...)
+		_, grpcWeb := GetGameIconGRPCWebHandler(impl, opts...)
+		mux.Handle(o.CORS(pattern, runtime.GRPCWebOr(grpcWeb, connect)))
+	}
+	mux.Handle(o.CORS(GetGameIconTwirpHandler(impl, opts...)))
+	{
+		pattern, connect := UploadGameIconConnectHandler(impl, opts...)
+		_, grpcWeb := UploadGameIconGRPCWebHandler(impl, opts...)
+		mux.Handle(o.CORS(pattern, runtime.GRPCWebOr(grpcWeb, connect)))
+	}
+	mux.Handle(o.CORS(UploadGameIconTwirpHandler(impl, opts...)))
+	mux.Handle(o.CORS(WatchGamesGRPCWebHandler(impl, opts...)))
+	o.HandlePreflight(mux, "/api/v1/gamelaunch/{id}", "POST")
+	o.HandlePreflight(mux, "/api/v1/games", "POST", "GET")
+	o.HandlePreflight(mux, "/api/v1/games/{id}/icon", "GET", "PUT")
+	o.HandlePreflight(mux, "/testv1.TestService/GameLaunch", "POST")
+	o.HandlePreflight(mux, "/twirp/testv1.TestService/GameLaunch", "POST")
+	o.HandlePreflight(mux, "/testv1.TestService/CreateGame", "POST")
+	o.HandlePreflight(mux, "/twirp/testv1.TestService/CreateGame", "POST")
+	o.HandlePreflight(mux, "/testv1.TestService/ListGames", "POST", "GET")
+	o.HandlePreflight(mux, "/twirp/testv1.TestService/ListGames", "POST")
+	o.HandlePreflight(mux, "/testv1.TestService/GetGameIcon", "POST", "GET")
+	o.HandlePreflight(mux, "/twirp/testv1.TestService/GetGameIcon", "POST")
+	o.HandlePreflight(mux, "/testv1.TestService/UploadGameIcon", "POST")
+	o.HandlePreflight(mux, "/twirp/testv1.TestService/UploadGameIcon", "POST")
+	o.HandlePreflight(mux, "/testv1.TestService/WatchGames", "POST")
+	o.HandleOpenAPI(mux, file_testv1_service_proto_openapi)
 	return
 }
 
+// TestServiceHTTPRoutes returns the routes RegisterHttpServer registers for TestService service:
+// the REST routes, then the routes of the RPC protocols, such as Connect, whose Protocol tells them apart.
+func TestServiceHTTPRoutes() []runtime.Route {
+	return []runtime.Route{
+		{
+			Protocol:   runtime.ProtocolREST,
+			HTTPMethod: "POST",
+			Template:   "/api/v1/gamelaunch/{id}",
+			Pattern:    "POST /api/v1/gamelaunch/{id}",
+			FullMethod: "/testv1.TestService/GameLaunch",
+			Input:      (*GameLaunchInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*GameLaunchResult)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolREST,
+			HTTPMethod: "POST",
+			Template:   "/api/v1/games",
+			Pattern:    "POST /api/v1/games",
+			FullMethod: "/testv1.TestService/CreateGame",
+			Input:      (*CreateGameInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolREST,
+			HTTPMethod: "GET",
+			Template:   "/api/v1/games",
+			Pattern:    "GET /api/v1/games",
+			FullMethod: "/testv1.TestService/ListGames",
+			Input:      (*ListGamesInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*ListGamesResult)(nil).ProtoReflect().Descriptor(),
+		},
+		{
+			Protocol:   runtime.ProtocolREST,
+			HTTPMethod: "GET",
+			Template:   "/api/v1/games/{id}/icon",
+			Pattern:    "GET /api/v1/games/{id}/icon",
+			FullMethod: "/testv1.TestService/GetGameIcon",
+			Input:      (*GetGameIconInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*httpbody.HttpBody)(nil).ProtoReflect().Descriptor(),
+		},
+		{
+			Protocol:   runtime.ProtocolREST,
+			HTTPMethod: "PUT",
+			Template:   "/api/v1/games/{id}/icon",
+			Pattern:    "PUT /api/v1/games/{id}/icon",
+			FullMethod: "/testv1.TestService/UploadGameIcon",
+			Input:      (*UploadGameIconInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "icon",
+		},
+		{
+			Protocol:   runtime.ProtocolConnect,
+			HTTPMethod: "",
+			Template:   "/testv1.TestService/GameLaunch",
+			Pattern:    "/testv1.TestService/GameLaunch",
+			FullMethod: "/testv1.TestService/GameLaunch",
+			Input:      (*GameLaunchInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*GameLaunchResult)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolTwirp,
+			HTTPMethod: "POST",
+			Template:   "/twirp/testv1.TestService/GameLaunch",
+			Pattern:    "POST /twirp/testv1.TestService/GameLaunch",
+			FullMethod: "/testv1.TestService/GameLaunch",
+			Input:      (*GameLaunchInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*GameLaunchResult)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolGRPCWeb,
+			HTTPMethod: "POST",
+			Template:   "/testv1.TestService/GameLaunch",
+			Pattern:    "/testv1.TestService/GameLaunch",
+			FullMethod: "/testv1.TestService/GameLaunch",
+			Input:      (*GameLaunchInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*GameLaunchResult)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolConnect,
+			HTTPMethod: "",
+			Template:   "/testv1.TestService/CreateGame",
+			Pattern:    "/testv1.TestService/CreateGame",
+			FullMethod: "/testv1.TestService/CreateGame",
+			Input:      (*CreateGameInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolTwirp,
+			HTTPMethod: "POST",
+			Template:   "/twirp/testv1.TestService/CreateGame",
+			Pattern:    "POST /twirp/testv1.TestService/CreateGame",
+			FullMethod: "/testv1.TestService/CreateGame",
+			Input:      (*CreateGameInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolGRPCWeb,
+			HTTPMethod: "POST",
+			Template:   "/testv1.TestService/CreateGame",
+			Pattern:    "/testv1.TestService/CreateGame",
+			FullMethod: "/testv1.TestService/CreateGame",
+			Input:      (*CreateGameInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolConnect,
+			HTTPMethod: "",
+			Template:   "/testv1.TestService/ListGames",
+			Pattern:    "/testv1.TestService/ListGames",
+			FullMethod: "/testv1.TestService/ListGames",
+			Input:      (*ListGamesInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*ListGamesResult)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolTwirp,
+			HTTPMethod: "POST",
+			Template:   "/twirp/testv1.TestService/ListGames",
+			Pattern:    "POST /twirp/testv1.TestService/ListGames",
+			FullMethod: "/testv1.TestService/ListGames",
+			Input:      (*ListGamesInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*ListGamesResult)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolGRPCWeb,
+			HTTPMethod: "POST",
+			Template:   "/testv1.TestService/ListGames",
+			Pattern:    "/testv1.TestService/ListGames",
+			FullMethod: "/testv1.TestService/ListGames",
+			Input:      (*ListGamesInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*ListGamesResult)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolConnect,
+			HTTPMethod: "",
+			Template:   "/testv1.TestService/GetGameIcon",
+			Pattern:    "/testv1.TestService/GetGameIcon",
+			FullMethod: "/testv1.TestService/GetGameIcon",
+			Input:      (*GetGameIconInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*httpbody.HttpBody)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolTwirp,
+			HTTPMethod: "POST",
+			Template:   "/twirp/testv1.TestService/GetGameIcon",
+			Pattern:    "POST /twirp/testv1.TestService/GetGameIcon",
+			FullMethod: "/testv1.TestService/GetGameIcon",
+			Input:      (*GetGameIconInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*httpbody.HttpBody)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolGRPCWeb,
+			HTTPMethod: "POST",
+			Template:   "/testv1.TestService/GetGameIcon",
+			Pattern:    "/testv1.TestService/GetGameIcon",
+			FullMethod: "/testv1.TestService/GetGameIcon",
+			Input:      (*GetGameIconInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*httpbody.HttpBody)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolConnect,
+			HTTPMethod: "",
+			Template:   "/testv1.TestService/UploadGameIcon",
+			Pattern:    "/testv1.TestService/UploadGameIcon",
+			FullMethod: "/testv1.TestService/UploadGameIcon",
+			Input:      (*UploadGameIconInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolTwirp,
+			HTTPMethod: "POST",
+			Template:   "/twirp/testv1.TestService/UploadGameIcon",
+			Pattern:    "POST /twirp/testv1.TestService/UploadGameIcon",
+			FullMethod: "/testv1.TestService/UploadGameIcon",
+			Input:      (*UploadGameIconInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolGRPCWeb,
+			HTTPMethod: "POST",
+			Template:   "/testv1.TestService/UploadGameIcon",
+			Pattern:    "/testv1.TestService/UploadGameIcon",
+			FullMethod: "/testv1.TestService/UploadGameIcon",
+			Input:      (*UploadGameIconInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+		{
+			Protocol:   runtime.ProtocolGRPCWeb,
+			HTTPMethod: "POST",
+			Template:   "/testv1.TestService/WatchGames",
+			Pattern:    "POST /testv1.TestService/WatchGames",
+			FullMethod: "/testv1.TestService/WatchGames",
+			Input:      (*WatchGamesInput)(nil).ProtoReflect().Descriptor(),
+			Output:     (*Game)(nil).ProtoReflect().Descriptor(),
+			Body:       "*",
+		},
+	}
+}
+
 // GameLaunch returns TestServiceHTTPService interface's GameLaunch converted to http.HandlerFunc.
-func GameLaunchHandler(srv TestServiceServer) (pattern string, hdr http.Handler) {
+func GameLaunchHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	o.CapTimeout(30 * time.Second)
 	pattern = "POST /api/v1/gamelaunch/{id}"
-	hdr = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-
+	hdr = o.Instrument("/testv1.TestService/GameLaunch", "/api/v1/gamelaunch/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := o.NewContext(r)
 		in := &GameLaunchInput{}
-		if r.Method != http.MethodGet {
-			reqba, err := io.ReadAll(r.Body)
-			if err != nil {
-				writeErr(w, err)
-				return
-			}
-			err = json.Unmarshal(reqba, in)
-			if err != nil {
-				writeErr(w, err)
-				return
-			}
+		var err error
+		err = o.DecodeBody(r, in)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		in.Id = r.PathValue("id")
+		err = o.IfMatch(ctx, in)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		err = o.CheckFields(ctx, (*GameLaunchResult)(nil).ProtoReflect().Descriptor())
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		o.Decoded(ctx, in)
+		var out *GameLaunchResult
+		func() {
+			defer o.Recover(r, "/testv1.TestService/GameLaunch", &err)
+			out, err = srv.GameLaunch(ctx, in)
+		}()
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		o.WriteResponse(ctx, w, http.StatusOK, out)
+	}))
+	return
+}
+
+// CreateGame returns TestServiceHTTPService interface's CreateGame converted to http.HandlerFunc.
+func CreateGameHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /api/v1/games"
+	hdr = o.Instrument("/testv1.TestService/CreateGame", "/api/v1/games", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := o.NewContext(r)
+		in := &CreateGameInput{}
+		var err error
+		err = o.DecodeBody(r, in)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		err = o.IfMatch(ctx, in)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		err = o.CheckFields(ctx, (*Game)(nil).ProtoReflect().Descriptor())
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		o.Decoded(ctx, in)
+		var out *Game
+		func() {
+			defer o.Recover(r, "/testv1.TestService/CreateGame", &err)
+			out, err = srv.CreateGame(ctx, in)
+		}()
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		o.WriteResponse(ctx, w, http.StatusCreated, out)
+	}))
+	return
+}
+
+// ListGames returns TestServiceHTTPService interface's ListGames converted to http.HandlerFunc.
+func ListGamesHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "GET /api/v1/games"
+	hdr = o.Instrument("/testv1.TestService/ListGames", "/api/v1/games", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := o.NewContext(r)
+		in := &ListGamesInput{}
+		var err error
+		err = o.DecodeQuery(r, in)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		in.PageSize, err = o.PageSize(in.PageSize)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		err = o.CheckFields(ctx, (*ListGamesResult)(nil).ProtoReflect().Descriptor())
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		o.Decoded(ctx, in)
+		var out *ListGamesResult
+		func() {
+			defer o.Recover(r, "/testv1.TestService/ListGames", &err)
+			out, err = srv.ListGames(ctx, in)
+		}()
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		o.SetNextPage(w, r, out.GetNextPageToken())
+		o.WriteResponse(ctx, w, http.StatusOK, out)
+	}))
+	return
+}
+
+// GetGameIcon returns TestServiceHTTPService interface's GetGameIcon converted to http.HandlerFunc.
+func GetGameIconHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "GET /api/v1/games/{id}/icon"
+	hdr = o.Instrument("/testv1.TestService/GetGameIcon", "/api/v1/games/{id}/icon", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := o.NewContext(r)
+		in := &GetGameIconInput{}
+		var err error
+		err = o.DecodeQuery(r, in)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
 		}
 		in.Id = r.PathValue("id")
-		out, err := srv.GameLaunch(ctx, in)
+		o.Decoded(ctx, in)
+		var out *httpbody.HttpBody
+		func() {
+			defer o.Recover(r, "/testv1.TestService/GetGameIcon", &err)
+			out, err = srv.GetGameIcon(ctx, in)
+		}()
 		if err != nil {
-			writeErr(w, err)
+			o.WriteError(ctx, w, err)
 			return
 		}
-		writeRsp(w, out)
-	})
+		o.WriteHttpBody(ctx, w, http.StatusOK, out)
+	}))
 	return
 }
+
+// UploadGameIcon returns TestServiceHTTPService interface's UploadGameIcon converted to http.HandlerFunc.
+func UploadGameIconHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "PUT /api/v1/games/{id}/icon"
+	hdr = o.Instrument("/testv1.TestService/UploadGameIcon", "/api/v1/games/{id}/icon", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := o.NewContext(r)
+		in := &UploadGameIconInput{}
+		var err error
+		err = o.DecodeQuery(r, in)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		in.Icon = &httpbody.HttpBody{}
+		err = o.ReadHttpBody(r, in.Icon)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		in.Id = r.PathValue("id")
+		err = o.IfMatch(ctx, in)
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		err = o.CheckFields(ctx, (*Game)(nil).ProtoReflect().Descriptor())
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		o.Decoded(ctx, in)
+		var out *Game
+		func() {
+			defer o.Recover(r, "/testv1.TestService/UploadGameIcon", &err)
+			out, err = srv.UploadGameIcon(ctx, in)
+		}()
+		if err != nil {
+			o.WriteError(ctx, w, err)
+			return
+		}
+		o.WriteResponse(ctx, w, http.StatusOK, out)
+	}))
+	return
+}
+
+// GameLaunchConnectHandler returns TestServiceServer's GameLaunch served with the Connect unary protocol.
+func GameLaunchConnectHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	o.CapTimeout(30 * time.Second)
+	pattern = "/testv1.TestService/GameLaunch"
+	hdr = o.Instrument("/testv1.TestService/GameLaunch", "/testv1.TestService/GameLaunch", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &GameLaunchInput{}
+		o.ServeConnect(w, r, false, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/GameLaunch", &err)
+			return srv.GameLaunch(ctx, in)
+		})
+	}))
+	return
+}
+
+// GameLaunchTwirpHandler returns TestServiceServer's GameLaunch served with the Twirp protocol.
+func GameLaunchTwirpHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	o.CapTimeout(30 * time.Second)
+	pattern = "POST /twirp/testv1.TestService/GameLaunch"
+	hdr = o.Instrument("/testv1.TestService/GameLaunch", "/twirp/testv1.TestService/GameLaunch", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &GameLaunchInput{}
+		o.ServeTwirp(w, r, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/GameLaunch", &err)
+			return srv.GameLaunch(ctx, in)
+		})
+	}))
+	return
+}
+
+// GameLaunchGRPCWebHandler returns TestServiceServer's GameLaunch served with the gRPC-Web protocol.
+func GameLaunchGRPCWebHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	o.CapTimeout(30 * time.Second)
+	pattern = "POST /testv1.TestService/GameLaunch"
+	hdr = o.Instrument("/testv1.TestService/GameLaunch", "/testv1.TestService/GameLaunch", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &GameLaunchInput{}
+		o.ServeGRPCWeb(w, r, in, func(stream *runtime.Stream) (err error) {
+			defer o.Recover(r, "/testv1.TestService/GameLaunch", &err)
+			out, err := srv.GameLaunch(stream.Context(), in)
+			if err != nil {
+				return err
+			}
+			return stream.SendMsg(out)
+		})
+	}))
+	return
+}
+
+// CreateGameConnectHandler returns TestServiceServer's CreateGame served with the Connect unary protocol.
+func CreateGameConnectHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "/testv1.TestService/CreateGame"
+	hdr = o.Instrument("/testv1.TestService/CreateGame", "/testv1.TestService/CreateGame", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &CreateGameInput{}
+		o.ServeConnect(w, r, false, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/CreateGame", &err)
+			return srv.CreateGame(ctx, in)
+		})
+	}))
+	return
+}
+
+// CreateGameTwirpHandler returns TestServiceServer's CreateGame served with the Twirp protocol.
+func CreateGameTwirpHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /twirp/testv1.TestService/CreateGame"
+	hdr = o.Instrument("/testv1.TestService/CreateGame", "/twirp/testv1.TestService/CreateGame", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &CreateGameInput{}
+		o.ServeTwirp(w, r, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/CreateGame", &err)
+			return srv.CreateGame(ctx, in)
+		})
+	}))
+	return
+}
+
+// CreateGameGRPCWebHandler returns TestServiceServer's CreateGame served with the gRPC-Web protocol.
+func CreateGameGRPCWebHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /testv1.TestService/CreateGame"
+	hdr = o.Instrument("/testv1.TestService/CreateGame", "/testv1.TestService/CreateGame", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &CreateGameInput{}
+		o.ServeGRPCWeb(w, r, in, func(stream *runtime.Stream) (err error) {
+			defer o.Recover(r, "/testv1.TestService/CreateGame", &err)
+			out, err := srv.CreateGame(stream.Context(), in)
+			if err != nil {
+				return err
+			}
+			return stream.SendMsg(out)
+		})
+	}))
+	return
+}
+
+// ListGamesConnectHandler returns TestServiceServer's ListGames served with the Connect unary protocol.
+func ListGamesConnectHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "/testv1.TestService/ListGames"
+	hdr = o.Instrument("/testv1.TestService/ListGames", "/testv1.TestService/ListGames", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &ListGamesInput{}
+		o.ServeConnect(w, r, true, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/ListGames", &err)
+			in.PageSize, err = o.PageSize(in.PageSize)
+			if err != nil {
+				return nil, err
+			}
+			return srv.ListGames(ctx, in)
+		})
+	}))
+	return
+}
+
+// ListGamesTwirpHandler returns TestServiceServer's ListGames served with the Twirp protocol.
+func ListGamesTwirpHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /twirp/testv1.TestService/ListGames"
+	hdr = o.Instrument("/testv1.TestService/ListGames", "/twirp/testv1.TestService/ListGames", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &ListGamesInput{}
+		o.ServeTwirp(w, r, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/ListGames", &err)
+			in.PageSize, err = o.PageSize(in.PageSize)
+			if err != nil {
+				return nil, err
+			}
+			return srv.ListGames(ctx, in)
+		})
+	}))
+	return
+}
+
+// ListGamesGRPCWebHandler returns TestServiceServer's ListGames served with the gRPC-Web protocol.
+func ListGamesGRPCWebHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /testv1.TestService/ListGames"
+	hdr = o.Instrument("/testv1.TestService/ListGames", "/testv1.TestService/ListGames", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &ListGamesInput{}
+		o.ServeGRPCWeb(w, r, in, func(stream *runtime.Stream) (err error) {
+			defer o.Recover(r, "/testv1.TestService/ListGames", &err)
+			in.PageSize, err = o.PageSize(in.PageSize)
+			if err != nil {
+				return err
+			}
+			out, err := srv.ListGames(stream.Context(), in)
+			if err != nil {
+				return err
+			}
+			return stream.SendMsg(out)
+		})
+	}))
+	return
+}
+
+// GetGameIconConnectHandler returns TestServiceServer's GetGameIcon served with the Connect unary protocol.
+func GetGameIconConnectHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "/testv1.TestService/GetGameIcon"
+	hdr = o.Instrument("/testv1.TestService/GetGameIcon", "/testv1.TestService/GetGameIcon", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &GetGameIconInput{}
+		o.ServeConnect(w, r, true, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/GetGameIcon", &err)
+			return srv.GetGameIcon(ctx, in)
+		})
+	}))
+	return
+}
+
+// GetGameIconTwirpHandler returns TestServiceServer's GetGameIcon served with the Twirp protocol.
+func GetGameIconTwirpHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /twirp/testv1.TestService/GetGameIcon"
+	hdr = o.Instrument("/testv1.TestService/GetGameIcon", "/twirp/testv1.TestService/GetGameIcon", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &GetGameIconInput{}
+		o.ServeTwirp(w, r, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/GetGameIcon", &err)
+			return srv.GetGameIcon(ctx, in)
+		})
+	}))
+	return
+}
+
+// GetGameIconGRPCWebHandler returns TestServiceServer's GetGameIcon served with the gRPC-Web protocol.
+func GetGameIconGRPCWebHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /testv1.TestService/GetGameIcon"
+	hdr = o.Instrument("/testv1.TestService/GetGameIcon", "/testv1.TestService/GetGameIcon", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &GetGameIconInput{}
+		o.ServeGRPCWeb(w, r, in, func(stream *runtime.Stream) (err error) {
+			defer o.Recover(r, "/testv1.TestService/GetGameIcon", &err)
+			out, err := srv.GetGameIcon(stream.Context(), in)
+			if err != nil {
+				return err
+			}
+			return stream.SendMsg(out)
+		})
+	}))
+	return
+}
+
+// UploadGameIconConnectHandler returns TestServiceServer's UploadGameIcon served with the Connect unary protocol.
+func UploadGameIconConnectHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "/testv1.TestService/UploadGameIcon"
+	hdr = o.Instrument("/testv1.TestService/UploadGameIcon", "/testv1.TestService/UploadGameIcon", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &UploadGameIconInput{}
+		o.ServeConnect(w, r, false, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/UploadGameIcon", &err)
+			return srv.UploadGameIcon(ctx, in)
+		})
+	}))
+	return
+}
+
+// UploadGameIconTwirpHandler returns TestServiceServer's UploadGameIcon served with the Twirp protocol.
+func UploadGameIconTwirpHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /twirp/testv1.TestService/UploadGameIcon"
+	hdr = o.Instrument("/testv1.TestService/UploadGameIcon", "/twirp/testv1.TestService/UploadGameIcon", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &UploadGameIconInput{}
+		o.ServeTwirp(w, r, in, func(ctx context.Context) (out proto.Message, err error) {
+			defer o.Recover(r, "/testv1.TestService/UploadGameIcon", &err)
+			return srv.UploadGameIcon(ctx, in)
+		})
+	}))
+	return
+}
+
+// UploadGameIconGRPCWebHandler returns TestServiceServer's UploadGameIcon served with the gRPC-Web protocol.
+func UploadGameIconGRPCWebHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /testv1.TestService/UploadGameIcon"
+	hdr = o.Instrument("/testv1.TestService/UploadGameIcon", "/testv1.TestService/UploadGameIcon", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &UploadGameIconInput{}
+		o.ServeGRPCWeb(w, r, in, func(stream *runtime.Stream) (err error) {
+			defer o.Recover(r, "/testv1.TestService/UploadGameIcon", &err)
+			out, err := srv.UploadGameIcon(stream.Context(), in)
+			if err != nil {
+				return err
+			}
+			return stream.SendMsg(out)
+		})
+	}))
+	return
+}
+
+// WatchGamesGRPCWebHandler returns TestServiceServer's WatchGames served with the gRPC-Web protocol.
+func WatchGamesGRPCWebHandler(srv TestServiceServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {
+	o := runtime.NewServerOptions(opts...)
+	pattern = "POST /testv1.TestService/WatchGames"
+	hdr = o.Instrument("/testv1.TestService/WatchGames", "/testv1.TestService/WatchGames", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &WatchGamesInput{}
+		o.ServeGRPCWeb(w, r, in, func(stream *runtime.Stream) (err error) {
+			defer o.Recover(r, "/testv1.TestService/WatchGames", &err)
+			return srv.WatchGames(in, runtime.ServerStream[Game]{Stream: stream})
+		})
+	}))
+	return
+}
+
+// ListGamesIter returns an iterator over the games of the pages of TestService's ListGames, from the page of in on.
+// list is called with the successive page tokens, e.g. a client's ListGames method.
+func ListGamesIter(ctx context.Context, list func(context.Context, *ListGamesInput) (*ListGamesResult, error), in *ListGamesInput) iter.Seq2[*Game, error] {
+	return runtime.Paginate(ctx, in, list, (*ListGamesResult).GetGames)
+}
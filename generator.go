@@ -1,33 +1,43 @@
 package main
 
 import (
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 const (
 	bytesPackage   = protogen.GoImportPath("bytes")
 	contextPackage = protogen.GoImportPath("context")
+	embedPackage   = protogen.GoImportPath("embed")
 	errorsPkg      = protogen.GoImportPath("errors")
 	jsonPackage    = protogen.GoImportPath("encoding/json")
 	fmtPackage     = protogen.GoImportPath("fmt")
 	ioPackage      = protogen.GoImportPath("io")
+	iterPackage    = protogen.GoImportPath("iter")
 	mimePackage    = protogen.GoImportPath("mime")
 	httpPackage    = protogen.GoImportPath("net/http")
 	strconvPackage = protogen.GoImportPath("strconv")
 	stringsPackage = protogen.GoImportPath("strings")
+	timePackage    = protogen.GoImportPath("time")
 	schemaPackage  = protogen.GoImportPath("github.com/gorilla/schema")
+	grpcPackage    = protogen.GoImportPath("google.golang.org/grpc")
+	protoPackage   = protogen.GoImportPath("google.golang.org/protobuf/proto")
+	runtimePackage = protogen.GoImportPath("github.com/peterchanxyz/protoc-gen-http-go/runtime")
 )
 
 // generateFile generates a _gin.pb.go file.
 func generateFile(gen *protogen.Plugin, file *protogen.File) (err error) {
-	if len(file.Services) == 0 {
+	if len(servedServices(file)) == 0 {
 		return nil
 	}
 	filename := file.GeneratedFilenamePrefix + "_http.pb.go"
@@ -37,18 +47,22 @@ func generateFile(gen *protogen.Plugin, file *protogen.File) (err error) {
 	g.P()
 	g.P("package ", file.GoPackageName)
 	g.P()
-	g.P("var (")
-	g.P("    queryDecoder = ", schemaPackage.Ident("NewDecoder"), "()")
-	g.P(")")
-	g.P()
-	g.P("func init() {")
-	g.P("    queryDecoder.SetAliasTag(\"json\")")
-	g.P("    queryDecoder.IgnoreUnknownKeys(true)")
-	g.P("}")
-	g.P()
 
-	for _, service := range file.Services {
-		err = genService(g, service)
+	spec := ""
+	if *serveOpenAPI {
+		err = generateOpenAPIJSON(gen, file)
+		if err != nil {
+			return
+		}
+		spec = openAPISpecVar(file)
+		g.Import(embedPackage)
+		g.P("//go:embed ", path.Base(file.GeneratedFilenamePrefix), ".openapi.json")
+		g.P("var ", spec, " []byte")
+		g.P()
+	}
+
+	for _, service := range servedServices(file) {
+		err = genService(gen, g, service, spec)
 		if err != nil {
 			return
 		}
@@ -56,7 +70,27 @@ func generateFile(gen *protogen.Plugin, file *protogen.File) (err error) {
 	return
 }
 
-func genService(g *protogen.GeneratedFile, s *protogen.Service) (err error) {
+func genService(gen *protogen.Plugin, g *protogen.GeneratedFile, s *protogen.Service, spec string) (err error) {
+	routes, err := buildRoutes(s)
+	if err != nil {
+		return err
+	}
+	pfs, err := preflights(s)
+	if err != nil {
+		return err
+	}
+	operations := map[*protogen.Method]*protogen.Message{}
+	for _, method := range servedMethods(s) {
+		if _, err := methodTimeout(method); err != nil {
+			return err
+		}
+		if isLongRunning(method) {
+			if operations[method], err = operationResponse(gen, method); err != nil {
+				return err
+			}
+		}
+	}
+
 	// service server interface
 	g.P("// ", s.GoName, "Server is the server API for ", s.GoName, " service.")
 	if isDeprecatedService(s) {
@@ -65,116 +99,243 @@ func genService(g *protogen.GeneratedFile, s *protogen.Service) (err error) {
 	}
 	g.P("type ", s.GoName, "Server interface {")
 
-	for _, method := range s.Methods {
-		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
-			continue
-		}
-
+	for _, method := range servedMethods(s) {
 		if comment := method.Comments.Leading.String(); comment != "" {
 			g.P(strings.TrimSpace(comment))
 		}
 		if isDeprecatedMethod(method) {
 			deprecated(g)
 		}
-		g.P("    ", method.GoName, "(", contextPackage.Ident("Context"), ", *", method.Input.GoIdent, ") (*", method.Output.GoIdent, ", error)")
+		if isUnary(method) {
+			g.P("    ", method.GoName, "(", contextPackage.Ident("Context"), ", *", method.Input.GoIdent, ") (*", method.Output.GoIdent, ", error)")
+		} else {
+			g.P("    ", method.GoName, "(*", method.Input.GoIdent, ", ", grpcPackage.Ident("ServerStreamingServer"), "[", method.Output.GoIdent, "]) error")
+		}
 	}
 	g.P("}")
 	g.P()
 
-	genWriteErr(g)
-	g.P()
-	genWriteRsp(g)
-
 	// g.P("// ", s.GoName, "RegisterHttpServer has a ", s.GoName, "HTTPService interface to http.HandlerFunc.")
-	g.P("func RegisterHttpServer(srv any, impl ", s.GoName, "Server) (err error) {")
+	g.P("func RegisterHttpServer(srv any, impl ", s.GoName, "Server, opts ...", runtimePackage.Ident("ServerOption"), ") (err error) {")
 	g.P("    mux, ok := srv.(interface { Handle(string, ", httpPackage.Ident("Handler"), ") })")
 	g.P("    if !ok {")
 	g.P("        err = ", errorsPkg.Ident("New"), "(\"srv must implement HttpServerMux\")")
 	g.P("        return")
 	g.P("    }")
+	g.P("    o := ", runtimePackage.Ident("NewServerOptions"), "(opts...)")
+	opsRoute := operationsRoute(s)
+	if opsRoute != nil {
+		op := opsRoute.Method.Output
+		g.P("    ops, ok := impl.(interface {")
+		g.P("        GetOperation(", contextPackage.Ident("Context"), ", *", operationRequest(op), ") (*", op.GoIdent, ", error)")
+		g.P("    })")
+		g.P("    if !ok {")
+		g.P("        err = ", errorsPkg.Ident("New"), "(\"impl must implement the GetOperation method of google.longrunning.Operations to serve the operations of ", s.GoName, " service\")")
+		g.P("        return")
+		g.P("    }")
+	}
 
-	for _, method := range s.Methods {
-		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+	for _, rt := range routes {
+		if host := grpcWebHost(rt.Method); *grpcWeb && host != nil && host.Protocol == protocolREST {
+			genHandleGRPCWebOr(g, rt.Method, "", "rest")
 			continue
 		}
-		g.P("    mux.Handle(", method.GoName, "Handler(impl))")
+		g.P("    mux.Handle(o.CORS(", rt.Method.GoName, "Handler(impl, opts...)))")
+	}
+	for _, method := range servedMethods(s) {
+		switch unary := isUnary(method); {
+		case unary && *connect && *grpcWeb:
+			genHandleGRPCWebOr(g, method, "Connect", "connect")
+		case unary && *connect:
+			g.P("    mux.Handle(o.CORS(", method.GoName, "ConnectHandler(impl, opts...)))")
+		case *grpcWeb && grpcWebHost(method) == nil:
+			g.P("    mux.Handle(o.CORS(", method.GoName, "GRPCWebHandler(impl, opts...)))")
+		}
+		if isUnary(method) && *twirp {
+			g.P("    mux.Handle(o.CORS(", method.GoName, "TwirpHandler(impl, opts...)))")
+		}
+	}
+	for _, pf := range pfs {
+		methods := make([]string, len(pf.Methods))
+		for i, method := range pf.Methods {
+			methods[i] = strconv.Quote(method)
+		}
+		g.P("    o.HandlePreflight(mux, ", strconv.Quote(pf.Path), ", ", strings.Join(methods, ", "), ")")
+	}
+	if opsRoute != nil {
+		g.P("    pattern, hdr := ", s.GoName, "OperationsHandler(ops, opts...)")
+		g.P("    o.HandleOperations(mux, pattern, hdr)")
+	}
+	if spec != "" {
+		g.P("    o.HandleOpenAPI(mux, ", spec, ")")
 	}
 	g.P("    return")
 	g.P("}")
 	g.P()
 
-	for _, method := range s.Methods {
-		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
-			continue
-		}
-		err = genMethod(g, method)
+	genRoutes(g, s, routes)
+
+	for _, rt := range routes {
+		err = genMethod(g, rt)
 		if err != nil {
 			return err
 		}
 	}
+	for _, method := range servedMethods(s) {
+		if isUnary(method) && *connect {
+			genProtocolMethod(g, connectRoute(method), "Connect", "the Connect unary protocol", isIdempotent(method))
+		}
+		if isUnary(method) && *twirp {
+			genProtocolMethod(g, twirpRoute(method), "Twirp", "the Twirp protocol")
+		}
+		if *grpcWeb {
+			genGRPCWebMethod(g, grpcWebRoute(method))
+		}
+	}
+	for _, method := range servedMethods(s) {
+		if items := pageItems(method); items != nil {
+			genPageIterator(g, method, items)
+		}
+	}
+	for _, method := range servedMethods(s) {
+		if resp, ok := operations[method]; ok {
+			genOperationWait(g, method, resp)
+		}
+	}
+	if opsRoute != nil {
+		genOperationsHandler(g, s, opsRoute.Method.Output)
+	}
 	return nil
 }
 
-func genMethod(g *protogen.GeneratedFile, m *protogen.Method) (err error) {
+// genHandleGRPCWebOr generates the registration of the XxxProtocolHandler of m, e.g. XxxConnectHandler, serving
+// the gRPC-Web requests of its path with the XxxGRPCWebHandler of m. name is the variable of the handler.
+func genHandleGRPCWebOr(g *protogen.GeneratedFile, m *protogen.Method, protocol, name string) {
+	g.P("    {")
+	g.P("        pattern, ", name, " := ", m.GoName, protocol, "Handler(impl, opts...)")
+	g.P("        _, grpcWeb := ", m.GoName, "GRPCWebHandler(impl, opts...)")
+	g.P("        mux.Handle(o.CORS(pattern, ", runtimePackage.Ident("GRPCWebOr"), "(grpcWeb, ", name, ")))")
+	g.P("    }")
+}
+
+// genPageIterator generates the XxxIter function iterating over the items of the pages of the paginated method m.
+func genPageIterator(g *protogen.GeneratedFile, m *protogen.Method, items *protogen.Field) {
+	item := goType(g, items)
+	g.P("// ", m.GoName, "Iter returns an iterator over the ", items.Desc.Name(), " of the pages of ", m.Parent.GoName, "'s ", m.GoName, ", from the page of in on.")
+	g.P("// list is called with the successive page tokens, e.g. a client's ", m.GoName, " method.")
+	g.P("func ", m.GoName, "Iter(ctx ", contextPackage.Ident("Context"), ", list func(", contextPackage.Ident("Context"), ", *", m.Input.GoIdent, ") (*", m.Output.GoIdent, ", error), in *", m.Input.GoIdent, ") ", iterPackage.Ident("Seq2"), "[", item, ", error] {")
+	g.P("    return ", runtimePackage.Ident("Paginate"), "(ctx, in, list, (*", m.Output.GoIdent, ").Get", items.GoName, ")")
+	g.P("}")
+	g.P()
+}
+
+func genMethod(g *protogen.GeneratedFile, rt *route) (err error) {
+	m := rt.Method
 	g.P("// ", m.GoName, " returns ", m.Parent.GoName, "HTTPService interface's ", m.GoName, " converted to http.HandlerFunc.")
 	if m.Comments.Leading.String() != "" {
 		g.P("//")
 	}
-	var httpMtd string
-	var pattern string
-	var pathParams []*pathParam
-	rule, ok := proto.GetExtension(m.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
-	if ok {
-		httpMtd, pattern = buildHTTPRule(m, rule)
-
-	} else {
-		httpMtd = "POST"
-		pattern = m.GoName
-	}
-
-	pathParams, err = parsePathParam(pattern)
-	if err != nil {
-		return err
-	}
-
-	g.P("func ", m.GoName, "Handler(srv ", m.Parent.GoName, "Server) (pattern string, hdr ", httpPackage.Ident("Handler"), ") {")
-	g.P("    pattern = ", "\"", httpMtd, " ", pattern, "\"")
-	g.P("    hdr = ", httpPackage.Ident("HandlerFunc"), "(func(w ", httpPackage.Ident("ResponseWriter"), ", r *", httpPackage.Ident("Request"), ") {")
-	g.P("        ctx := r.Context()")
+	g.P("func ", m.GoName, "Handler(srv ", m.Parent.GoName, "Server, opts ...", runtimePackage.Ident("ServerOption"), ") (pattern string, hdr ", httpPackage.Ident("Handler"), ") {")
+	g.P("    o := ", runtimePackage.Ident("NewServerOptions"), "(opts...)")
+	genTimeout(g, m)
+	g.P("    pattern = ", strconv.Quote(rt.Pattern()))
+	g.P("    hdr = o.Instrument(", strconv.Quote(rt.FullMethod()), ", ", strconv.Quote(rt.Path), ", ", httpPackage.Ident("HandlerFunc"), "(func(w ", httpPackage.Ident("ResponseWriter"), ", r *", httpPackage.Ident("Request"), ") {")
+	g.P("        ctx := o.NewContext(r)")
 	g.P("        in := &", m.Input.GoIdent, "{}")
 	g.P("        var err error")
 
-	if httpMtd != "GET" {
-		g.P("        var reqba []byte")
-		g.P("        reqba, err = ", ioPackage.Ident("ReadAll"), "(r.Body)")
+	bodyField := rt.BodyField
+	switch {
+	case rt.HTTPMethod != "GET" && isHttpBody(m.Input):
+		g.P("        err = o.ReadHttpBody(r, in)")
 		g.P("        if err != nil {")
-		g.P("            writeErr(w, err)")
+		g.P("            o.WriteError(ctx, w, err)")
 		g.P("            return")
 		g.P("        }")
-		g.P("        err = ", jsonPackage.Ident("Unmarshal"), "(reqba, in)")
+	case rt.HTTPMethod != "GET" && bodyField != nil && isHttpBody(bodyField.Message):
+		g.P("        err = o.DecodeQuery(r, in)")
 		g.P("        if err != nil {")
-		g.P("            writeErr(w, err)")
+		g.P("            o.WriteError(ctx, w, err)")
 		g.P("            return")
 		g.P("        }")
-	} else {
-		g.P("        err = queryDecoder.Decode(in, r.URL.Query())")
+		g.P("        in.", bodyField.GoName, " = &", bodyField.Message.GoIdent, "{}")
+		g.P("        err = o.ReadHttpBody(r, in.", bodyField.GoName, ")")
 		g.P("        if err != nil {")
-		g.P("            writeErr(w, err)")
+		g.P("            o.WriteError(ctx, w, err)")
+		g.P("            return")
+		g.P("        }")
+	case isUpdateMaskInferred(rt):
+		g.P("        err = o.DecodeQuery(r, in)")
+		g.P("        if err != nil {")
+		g.P("            o.WriteError(ctx, w, err)")
+		g.P("            return")
+		g.P("        }")
+		g.P("        err = o.DecodeUpdateBody(r, in, ", strconv.Quote(string(bodyField.Desc.Name())), ")")
+		g.P("        if err != nil {")
+		g.P("            o.WriteError(ctx, w, err)")
+		g.P("            return")
+		g.P("        }")
+	case rt.HTTPMethod != "GET":
+		g.P("        err = o.DecodeBody(r, in)")
+		g.P("        if err != nil {")
+		g.P("            o.WriteError(ctx, w, err)")
+		g.P("            return")
+		g.P("        }")
+	default:
+		g.P("        err = o.DecodeQuery(r, in)")
+		g.P("        if err != nil {")
+		g.P("            o.WriteError(ctx, w, err)")
 		g.P("            return")
 		g.P("        }")
 	}
 
-	for _, t := range pathParams {
+	for _, t := range rt.PathParams {
 		g.P("in.", t.GoName, " = r.PathValue(\"", t.Name, "\")")
 	}
+	if rt.HTTPMethod != "GET" {
+		g.P("        err = o.IfMatch(ctx, in)")
+		g.P("        if err != nil {")
+		g.P("            o.WriteError(ctx, w, err)")
+		g.P("            return")
+		g.P("        }")
+	}
+	if pageItems(m) != nil {
+		g.P("        in.PageSize, err = o.PageSize(in.PageSize)")
+		g.P("        if err != nil {")
+		g.P("            o.WriteError(ctx, w, err)")
+		g.P("            return")
+		g.P("        }")
+	}
+	if !isHttpBody(m.Output) {
+		g.P("        err = o.CheckFields(ctx, (*", m.Output.GoIdent, ")(nil).ProtoReflect().Descriptor())")
+		g.P("        if err != nil {")
+		g.P("            o.WriteError(ctx, w, err)")
+		g.P("            return")
+		g.P("        }")
+	}
+	g.P("        o.Decoded(ctx, in)")
 
-	g.P("		out, err := srv.", m.GoName, "(ctx, in)")
+	g.P("		var out *", m.Output.GoIdent)
+	g.P("		func() {")
+	g.P("			defer o.Recover(r, ", strconv.Quote(rt.FullMethod()), ", &err)")
+	g.P("			out, err = srv.", m.GoName, "(ctx, in)")
+	g.P("		}()")
 	g.P("		if err != nil {")
-	g.P("			writeErr(w, err)")
+	g.P("			o.WriteError(ctx, w, err)")
 	g.P("			return")
 	g.P("		}")
-	g.P("		writeRsp(w, out)")
-	g.P("    })")
+	if pageItems(m) != nil {
+		g.P("		o.SetNextPage(w, r, out.GetNextPageToken())")
+	}
+	if isLongRunning(m) {
+		g.P("		o.SetOperationLocation(w, out.GetName())")
+	}
+	if isHttpBody(m.Output) {
+		g.P("		o.WriteHttpBody(ctx, w, ", statusIdent(rt.SuccessCode), ", out)")
+	} else {
+		g.P("		o.WriteResponse(ctx, w, ", statusIdent(rt.SuccessCode), ", out)")
+	}
+	g.P("    }))")
 	g.P("    return")
 	g.P("}")
 	g.P()
@@ -182,29 +343,178 @@ func genMethod(g *protogen.GeneratedFile, m *protogen.Method) (err error) {
 	return nil
 }
 
-func genWriteErr(g *protogen.GeneratedFile) {
-	g.P("func writeErr(w ", httpPackage.Ident("ResponseWriter"), ", err error) {")
-	g.P("    w.Header().Set(\"Content-Type\", \"application/json\")")
-	g.P("	 w.WriteHeader(", httpPackage.Ident("StatusBadRequest"), ")")
-	g.P("    errRst := map[string]any{}")
-	g.P("    errRst[\"message\"] = err.Error()")
-	g.P("    if cerr, ok := err.(interface{ Code() int }); ok {")
-	g.P("        errRst[\"code\"] = cerr.Code()")
-	g.P("    }")
-	g.P("	 jenc := ", jsonPackage.Ident("NewEncoder"), "(w)")
-	g.P("	 jenc.SetEscapeHTML(false)")
-	g.P("	 jenc.Encode(errRst)")
+// genProtocolMethod generates the XxxProtocolHandler serving the method of rt with the runtime ServeProtocol
+// method of an RPC protocol, e.g. ServeConnect, passing it args ahead of the request message.
+func genProtocolMethod(g *protogen.GeneratedFile, rt *route, protocol, description string, args ...any) {
+	m := rt.Method
+	name := m.GoName + protocol + "Handler"
+	g.P("// ", name, " returns ", m.Parent.GoName, "Server's ", m.GoName, " served with ", description, ".")
+	g.P("func ", name, "(srv ", m.Parent.GoName, "Server, opts ...", runtimePackage.Ident("ServerOption"), ") (pattern string, hdr ", httpPackage.Ident("Handler"), ") {")
+	g.P("    o := ", runtimePackage.Ident("NewServerOptions"), "(opts...)")
+	genTimeout(g, m)
+	g.P("    pattern = ", strconv.Quote(rt.Pattern()))
+	g.P("    hdr = o.Instrument(", strconv.Quote(rt.FullMethod()), ", ", strconv.Quote(rt.Path), ", ", httpPackage.Ident("HandlerFunc"), "(func(w ", httpPackage.Ident("ResponseWriter"), ", r *", httpPackage.Ident("Request"), ") {")
+	g.P("        in := &", m.Input.GoIdent, "{}")
+	call := []any{"        o.Serve", protocol, "(w, r, "}
+	for _, arg := range args {
+		call = append(call, arg, ", ")
+	}
+	call = append(call, "in, func(ctx ", contextPackage.Ident("Context"), ") (out ", protoPackage.Ident("Message"), ", err error) {")
+	g.P(call...)
+	g.P("            defer o.Recover(r, ", strconv.Quote(rt.FullMethod()), ", &err)")
+	if pageItems(m) != nil {
+		g.P("            in.PageSize, err = o.PageSize(in.PageSize)")
+		g.P("            if err != nil {")
+		g.P("                return nil, err")
+		g.P("            }")
+	}
+	g.P("            return srv.", m.GoName, "(ctx, in)")
+	g.P("        })")
+	g.P("    }))")
+	g.P("    return")
+	g.P("}")
+	g.P()
+}
+
+func genGRPCWebMethod(g *protogen.GeneratedFile, rt *route) {
+	m := rt.Method
+	g.P("// ", m.GoName, "GRPCWebHandler returns ", m.Parent.GoName, "Server's ", m.GoName, " served with the gRPC-Web protocol.")
+	g.P("func ", m.GoName, "GRPCWebHandler(srv ", m.Parent.GoName, "Server, opts ...", runtimePackage.Ident("ServerOption"), ") (pattern string, hdr ", httpPackage.Ident("Handler"), ") {")
+	g.P("    o := ", runtimePackage.Ident("NewServerOptions"), "(opts...)")
+	genTimeout(g, m)
+	g.P("    pattern = ", strconv.Quote(rt.Pattern()))
+	g.P("    hdr = o.Instrument(", strconv.Quote(rt.FullMethod()), ", ", strconv.Quote(rt.Path), ", ", httpPackage.Ident("HandlerFunc"), "(func(w ", httpPackage.Ident("ResponseWriter"), ", r *", httpPackage.Ident("Request"), ") {")
+	g.P("        in := &", m.Input.GoIdent, "{}")
+	g.P("        o.ServeGRPCWeb(w, r, in, func(stream *", runtimePackage.Ident("Stream"), ") (err error) {")
+	g.P("            defer o.Recover(r, ", strconv.Quote(rt.FullMethod()), ", &err)")
+	if isUnary(m) {
+		if pageItems(m) != nil {
+			g.P("            in.PageSize, err = o.PageSize(in.PageSize)")
+			g.P("            if err != nil {")
+			g.P("                return err")
+			g.P("            }")
+		}
+		g.P("            out, err := srv.", m.GoName, "(stream.Context(), in)")
+		g.P("            if err != nil {")
+		g.P("                return err")
+		g.P("            }")
+		g.P("            return stream.SendMsg(out)")
+	} else {
+		g.P("            return srv.", m.GoName, "(in, ", runtimePackage.Ident("ServerStream"), "[", m.Output.GoIdent, "]{Stream: stream})")
+	}
+	g.P("        })")
+	g.P("    }))")
+	g.P("    return")
 	g.P("}")
+	g.P()
+}
+
+// genTimeout caps the timeout of the handler of m with its timeout option, validated by genService.
+func genTimeout(g *protogen.GeneratedFile, m *protogen.Method) {
+	timeout, _ := methodTimeout(m)
+	if timeout <= 0 {
+		return
+	}
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "Hour"},
+		{time.Minute, "Minute"},
+		{time.Second, "Second"},
+		{time.Millisecond, "Millisecond"},
+		{time.Microsecond, "Microsecond"},
+		{time.Nanosecond, "Nanosecond"},
+	} {
+		if timeout%unit.d == 0 {
+			g.P("    o.CapTimeout(", int64(timeout/unit.d), " * ", timePackage.Ident(unit.name), ")")
+			return
+		}
+	}
 }
 
-func genWriteRsp(g *protogen.GeneratedFile) {
-	g.P("func writeRsp(w ", httpPackage.Ident("ResponseWriter"), ", resp any) {")
-	g.P("	 w.Header().Set(\"Content-Type\", \"application/json\")")
-	g.P("	 w.WriteHeader(", httpPackage.Ident("StatusOK"), ")")
-	g.P("	 jenc := ", jsonPackage.Ident("NewEncoder"), "(w)")
-	g.P("	 jenc.SetEscapeHTML(false)")
-	g.P("	 jenc.Encode(resp)")
+// genRoutes generates the XxxHTTPRoutes function returning the REST routes of s and the routes of the RPC protocols.
+func genRoutes(g *protogen.GeneratedFile, s *protogen.Service, routes []*route) {
+	routes = slices.Clone(routes)
+	for _, method := range servedMethods(s) {
+		routes = append(routes, rpcRoutes(method)...)
+		if *grpcWeb && grpcWebHost(method) != nil {
+			routes = append(routes, grpcWebRoute(method))
+		}
+	}
+	g.P("// ", s.GoName, "HTTPRoutes returns the routes RegisterHttpServer registers for ", s.GoName, " service:")
+	if operationsRoute(s) == nil {
+		g.P("// the REST routes, then the routes of the RPC protocols, such as Connect, whose Protocol tells them apart.")
+	} else {
+		g.P("// the REST routes, then the routes of the RPC protocols, such as Connect, whose Protocol tells them apart,")
+		g.P("// and last the operations route of the long-running methods.")
+	}
+	g.P("func ", s.GoName, "HTTPRoutes() []", runtimePackage.Ident("Route"), " {")
+	g.P("    return []", runtimePackage.Ident("Route"), "{")
+	for _, rt := range routes {
+		pattern := rt.Pattern()
+		if host := grpcWebHost(rt.Method); rt.Protocol == protocolGRPCWeb && host != nil {
+			pattern = host.Pattern()
+		}
+		g.P("        {")
+		g.P("            Protocol: ", runtimePackage.Ident(protocolNames[rt.Protocol]), ",")
+		g.P("            HTTPMethod: ", strconv.Quote(rt.HTTPMethod), ",")
+		g.P("            Template: ", strconv.Quote(rt.Path), ",")
+		g.P("            Pattern: ", strconv.Quote(pattern), ",")
+		g.P("            FullMethod: ", strconv.Quote(rt.FullMethod()), ",")
+		g.P("            Input: (*", rt.Method.Input.GoIdent, ")(nil).ProtoReflect().Descriptor(),")
+		g.P("            Output: (*", rt.Method.Output.GoIdent, ")(nil).ProtoReflect().Descriptor(),")
+		if rt.Body != "" {
+			g.P("            Body: ", strconv.Quote(rt.Body), ",")
+		}
+		if rt.ResponseBody != "" {
+			g.P("            ResponseBody: ", strconv.Quote(rt.ResponseBody), ",")
+		}
+		if isDeprecatedMethod(rt.Method) {
+			g.P("            Deprecated: true,")
+		}
+		g.P("        },")
+	}
+	if rt := operationsRoute(s); rt != nil {
+		g.P("        {")
+		g.P("            Protocol: ", runtimePackage.Ident("ProtocolREST"), ",")
+		g.P("            HTTPMethod: ", strconv.Quote(rt.HTTPMethod), ",")
+		g.P("            Template: ", strconv.Quote(rt.Path), ",")
+		g.P("            Pattern: ", strconv.Quote(rt.Pattern()), ",")
+		g.P("            FullMethod: \"/google.longrunning.Operations/GetOperation\",")
+		g.P("            Input: (*", operationRequest(rt.Method.Output), ")(nil).ProtoReflect().Descriptor(),")
+		g.P("            Output: (*", rt.Method.Output.GoIdent, ")(nil).ProtoReflect().Descriptor(),")
+		g.P("        },")
+	}
+	g.P("    }")
 	g.P("}")
+	g.P()
+}
+
+var protocolNames = map[string]string{
+	protocolREST:    "ProtocolREST",
+	protocolConnect: "ProtocolConnect",
+	protocolTwirp:   "ProtocolTwirp",
+	protocolGRPCWeb: "ProtocolGRPCWeb",
+}
+
+// statusIdent returns the net/http constant for code, falling back to the number itself.
+func statusIdent(code int) any {
+	name, ok := statusNames[code]
+	if !ok {
+		return code
+	}
+	return httpPackage.Ident(name)
+}
+
+var statusNames = map[int]string{
+	http.StatusOK:                   "StatusOK",
+	http.StatusCreated:              "StatusCreated",
+	http.StatusAccepted:             "StatusAccepted",
+	http.StatusNonAuthoritativeInfo: "StatusNonAuthoritativeInfo",
+	http.StatusNoContent:            "StatusNoContent",
+	http.StatusResetContent:         "StatusResetContent",
+	http.StatusPartialContent:       "StatusPartialContent",
 }
 
 func isDeprecatedService(service *protogen.Service) bool {
@@ -0,0 +1,776 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// newTestPlugin returns a plugin run with param over files, given as FileDescriptorProto text.
+// The files may import any proto file linked into the test binary, such as google/api/annotations.proto.
+func newTestPlugin(t *testing.T, param string, files ...string) *protogen.Plugin {
+	t.Helper()
+
+	saved := map[string]string{}
+	flags.VisitAll(func(f *flag.Flag) {
+		saved[f.Name] = f.Value.String()
+	})
+	t.Cleanup(func() {
+		for name, value := range saved {
+			flags.Set(name, value)
+		}
+	})
+
+	req := &pluginpb.CodeGeneratorRequest{Parameter: proto.String(param)}
+	seen := map[string]bool{}
+	var addDep func(path string)
+	addDep = func(path string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		fd, err := protoregistry.GlobalFiles.FindFileByPath(path)
+		if err != nil {
+			t.Fatalf("dependency %s: %v", path, err)
+		}
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			addDep(imports.Get(i).Path())
+		}
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, src := range files {
+		fdp := &descriptorpb.FileDescriptorProto{}
+		if err := prototext.Unmarshal([]byte(src), fdp); err != nil {
+			t.Fatalf("prototext.Unmarshal(%q) failed with %v", src, err)
+		}
+		for _, dep := range fdp.Dependency {
+			if !seen[dep] {
+				addDep(dep)
+			}
+		}
+		seen[fdp.GetName()] = true
+		req.ProtoFile = append(req.ProtoFile, fdp)
+		req.FileToGenerate = append(req.FileToGenerate, fdp.GetName())
+	}
+
+	gen, err := protogen.Options{ParamFunc: flags.Set}.New(req)
+	if err != nil {
+		t.Fatalf("protogen.Options.New() failed with %v", err)
+	}
+	return gen
+}
+
+// generatedContent returns the content of the generated file with the name, failing the test if there is none.
+func generatedContent(t *testing.T, gen *protogen.Plugin, name string) string {
+	t.Helper()
+	for _, f := range gen.Response().File {
+		if f.GetName() == name {
+			return f.GetContent()
+		}
+	}
+	t.Fatalf("no generated file %s", name)
+	return ""
+}
+
+// generateTestFiles runs generateFile over the files of gen to generate.
+func generateTestFiles(t *testing.T, gen *protogen.Plugin) error {
+	t.Helper()
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		if err := generateFile(gen, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestGenerateRoutes(t *testing.T) {
+	gen := newTestPlugin(t, "", libraryProto)
+	if err := checkRoutes(gen); err != nil {
+		t.Fatalf("checkRoutes() failed with %v", err)
+	}
+	if err := generateTestFiles(t, gen); err != nil {
+		t.Fatalf("generateFile() failed with %v", err)
+	}
+	code := generatedContent(t, gen, "example.com/library/v1/library_http.pb.go")
+	for _, want := range []string{
+		"func LibraryHTTPRoutes() []runtime.Route {",
+		"Protocol:   runtime.ProtocolREST,",
+		`Template:   "/v1/books/{name}",`,
+		`Pattern:    "GET /v1/books/{name}",`,
+		`FullMethod: "/library.v1.Library/GetBook",`,
+		"Input:      (*GetBookRequest)(nil).ProtoReflect().Descriptor(),",
+		`Body:       "*",`,
+		// The fields of partial responses are checked before the implementation is called.
+		"err = o.CheckFields(ctx, (*Book)(nil).ProtoReflect().Descriptor())\n\t\tif err != nil {\n\t\t\to.WriteError(ctx, w, err)\n\t\t\treturn\n\t\t}\n\t\to.Decoded(ctx, in)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+	// Only DeleteBook copies If-Match, GET methods answer If-None-Match when writing their response.
+	if got := strings.Count(code, "err = o.IfMatch(ctx, in)"); got != 1 {
+		t.Errorf("generated code copies If-Match in %d handlers, want 1:\n%s", got, code)
+	}
+}
+
+func TestCheckRoutes(t *testing.T) {
+	const shelfProto = `
+name: "library/v1/shelf.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: { name: "Shelf" }
+service: {
+	name: "Shelves"
+	method: {
+		name: "GetShelf"
+		input_type: ".library.v1.Shelf"
+		output_type: ".library.v1.Shelf"
+		options: { [google.api.http]: { get: "/v1/{shelf}" } }
+	}
+	method: {
+		name: "GetBook"
+		input_type: ".library.v1.Shelf"
+		output_type: ".library.v1.Shelf"
+		options: { [google.api.http]: { get: "/v1/{book}" } }
+	}
+	method: {
+		name: "ListBooks"
+		input_type: ".library.v1.Shelf"
+		output_type: ".library.v1.Shelf"
+		options: { [google.api.http]: { get: "/v1/{shelf}/books" } }
+	}
+}
+source_code_info: {
+	location: { path: [6, 0, 2, 0] span: [8, 2, 10, 3] }
+	location: { path: [6, 0, 2, 1] span: [11, 2, 13, 3] }
+}
+`
+	tests := []struct {
+		name  string
+		proto string
+		want  string
+	}{
+		{
+			name:  "same requests",
+			proto: shelfProto,
+			want: `library/v1/shelf.proto:12:3: library.v1.Shelves.GetBook: route "GET /v1/{book}" conflicts with route "GET /v1/{shelf}" ` +
+				`of library.v1.Shelves.GetShelf at library/v1/shelf.proto:9:3: GET /v1/{book} matches the same requests as GET /v1/{shelf}`,
+		},
+		{
+			name:  "ambiguous",
+			proto: strings.Replace(shelfProto, `get: "/v1/{book}"`, `get: "/v1/books/{book}"`, 1),
+			want:  `library.v1.Shelves.ListBooks: route "GET /v1/{shelf}/books" conflicts with route "GET /v1/books/{book}" of library.v1.Shelves.GetBook`,
+		},
+		{
+			name:  "invalid",
+			proto: strings.Replace(libraryProto, `get: "/v1/books/{name}"`, `get: "/v1/{name=books/*}"`, 1),
+			want:  `library/v1/library.proto: library.v1.Library.GetBook: invalid route "GET /v1/{name=books/*}"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRoutes(newTestPlugin(t, "", tt.proto))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("checkRoutes() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	gen := newTestPlugin(t, "", strings.Replace(shelfProto, `get: "/v1/{book}"`, `post: "/v1/{book}"`, 1))
+	if err := checkRoutes(gen); err != nil {
+		t.Errorf("checkRoutes() failed with %v", err)
+	}
+
+	// The routes of two services sharing a path do not conflict, their preflight routes do.
+	const shelfAdminProto = `
+name: "library/v1/shelf_admin.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+dependency: "library/v1/shelf.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+service: {
+	name: "ShelfAdmin"
+	method: {
+		name: "DeleteShelf"
+		input_type: ".library.v1.Shelf"
+		output_type: ".library.v1.Shelf"
+		options: { [google.api.http]: { delete: "/v1/{shelf}" } }
+	}
+}
+`
+	gen = newTestPlugin(t, "", strings.Replace(shelfProto, `get: "/v1/{book}"`, `post: "/v1/{book}"`, 1), shelfAdminProto)
+	want := `library.v1.ShelfAdmin.DeleteShelf: route "OPTIONS /v1/{shelf}" conflicts with route "OPTIONS /v1/{shelf}" of library.v1.Shelves.GetShelf`
+	if err := checkRoutes(gen); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("checkRoutes() = %v, want an error containing %q", err, want)
+	}
+}
+
+func TestDefaultRoutes(t *testing.T) {
+	const adminProto = `
+syntax: "proto3"
+name: "library/v1/admin.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+dependency: "http_go/options.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: { name: "Empty" }
+service: {
+	name: "LibraryAdmin"
+	method: { name: "ReindexHTTPCache" input_type: ".library.v1.Empty" output_type: ".library.v1.Empty" }
+	method: {
+		name: "Purge"
+		input_type: ".library.v1.Empty"
+		output_type: ".library.v1.Empty"
+		options: {
+			[google.api.http]: { post: "/v1/purge" body: "*" }
+			[http_go.method]: { exclude: true }
+		}
+	}
+}
+service: {
+	name: "Internal"
+	options: { [http_go.service]: { exclude: true } }
+	method: { name: "Ping" input_type: ".library.v1.Empty" output_type: ".library.v1.Empty" }
+}
+`
+	tests := []struct {
+		param string
+		want  []string
+	}{
+		{param: "", want: []string{`"POST /library.v1.LibraryAdmin/ReindexHTTPCache"`}},
+		{param: "default_routes=rest", want: []string{`"POST /library/v1/library-admin/reindex-http-cache"`}},
+		{param: "default_routes=none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			gen := newTestPlugin(t, tt.param, adminProto)
+			if err := generateTestFiles(t, gen); err != nil {
+				t.Fatalf("generateFile() failed with %v", err)
+			}
+			code := generatedContent(t, gen, "example.com/library/v1/admin_http.pb.go")
+			for _, want := range tt.want {
+				if !strings.Contains(code, want) {
+					t.Errorf("generated code does not contain %q:\n%s", want, code)
+				}
+			}
+			if got := strings.Count(code, "Pattern: "); got != len(tt.want) {
+				t.Errorf("generated %d routes, want %d:\n%s", got, len(tt.want), code)
+			}
+			for _, excluded := range []string{"Purge", "Internal"} {
+				if strings.Contains(code, excluded) {
+					t.Errorf("generated code contains excluded method %s:\n%s", excluded, code)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateProtocols(t *testing.T) {
+	const searchProto = `
+syntax: "proto3"
+name: "library/v1/search.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: { name: "Query" field: { name: "q" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING } }
+service: {
+	name: "Search"
+	method: {
+		name: "Search"
+		input_type: ".library.v1.Query"
+		output_type: ".library.v1.Query"
+		options: {
+			idempotency_level: NO_SIDE_EFFECTS
+			[google.api.http]: { get: "/v1/search" }
+		}
+	}
+	method: { name: "Reindex" input_type: ".library.v1.Query" output_type: ".library.v1.Query" }
+}
+`
+	gen := newTestPlugin(t, "connect=true,twirp=true", searchProto)
+	if err := checkRoutes(gen); err != nil {
+		t.Fatalf("checkRoutes() failed with %v", err)
+	}
+	if err := generateTestFiles(t, gen); err != nil {
+		t.Fatalf("generateFile() failed with %v", err)
+	}
+	code := generatedContent(t, gen, "example.com/library/v1/search_http.pb.go")
+	for _, want := range []string{
+		"Reindex(context.Context, *Query) (*Query, error)",
+		"mux.Handle(o.CORS(SearchHandler(impl, opts...)))",
+		"mux.Handle(o.CORS(SearchConnectHandler(impl, opts...)))",
+		"mux.Handle(o.CORS(ReindexConnectHandler(impl, opts...)))",
+		`pattern = "/library.v1.Search/Search"`,
+		"o.ServeConnect(w, r, true, in, func(ctx context.Context) (out proto.Message, err error) {",
+		`pattern = "/library.v1.Search/Reindex"`,
+		"o.ServeConnect(w, r, false, in, func(ctx context.Context) (out proto.Message, err error) {",
+		"mux.Handle(o.CORS(ReindexTwirpHandler(impl, opts...)))",
+		`pattern = "POST /twirp/library.v1.Search/Reindex"`,
+		"o.ServeTwirp(w, r, in, func(ctx context.Context) (out proto.Message, err error) {",
+		`defer o.Recover(r, "/library.v1.Search/Reindex", &err)`,
+		"o.Decoded(ctx, in)",
+		`hdr = o.Instrument("/library.v1.Search/Reindex", "/twirp/library.v1.Search/Reindex", http.HandlerFunc(`,
+		// The route table lists the routes of the RPC protocols along the REST ones.
+		"Protocol:   runtime.ProtocolConnect,\n\t\t\tHTTPMethod: \"\",\n\t\t\tTemplate:   \"/library.v1.Search/Reindex\",\n\t\t\tPattern:    \"/library.v1.Search/Reindex\",",
+		"Protocol:   runtime.ProtocolTwirp,\n\t\t\tHTTPMethod: \"POST\",\n\t\t\tTemplate:   \"/twirp/library.v1.Search/Reindex\",\n\t\t\tPattern:    \"POST /twirp/library.v1.Search/Reindex\",",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+	// The Connect handler serves the path of the rpc default route in place of a REST handler.
+	if strings.Contains(code, "func ReindexHandler(") {
+		t.Errorf("generated code contains a REST handler for Reindex:\n%s", code)
+	}
+}
+
+func TestGenerateGRPCWeb(t *testing.T) {
+	const watchProto = `
+syntax: "proto3"
+name: "library/v1/watch.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: { name: "Event" }
+service: {
+	name: "Watcher"
+	method: { name: "Get" input_type: ".library.v1.Event" output_type: ".library.v1.Event" }
+	method: { name: "Watch" input_type: ".library.v1.Event" output_type: ".library.v1.Event" server_streaming: true }
+	method: { name: "Upload" input_type: ".library.v1.Event" output_type: ".library.v1.Event" client_streaming: true }
+}
+`
+	for _, param := range []string{"grpc_web=true,connect=false", "grpc_web=true,connect=true", "grpc_web=true,default_routes=none"} {
+		t.Run(param, func(t *testing.T) {
+			gen := newTestPlugin(t, param, watchProto)
+			if err := checkRoutes(gen); err != nil {
+				t.Fatalf("checkRoutes() failed with %v", err)
+			}
+			if err := generateTestFiles(t, gen); err != nil {
+				t.Fatalf("generateFile() failed with %v", err)
+			}
+			code := generatedContent(t, gen, "example.com/library/v1/watch_http.pb.go")
+			wants := []string{
+				"Get(context.Context, *Event) (*Event, error)",
+				"Watch(*Event, grpc.ServerStreamingServer[Event]) error",
+				"mux.Handle(o.CORS(WatchGRPCWebHandler(impl, opts...)))",
+				`pattern = "POST /library.v1.Watcher/Watch"`,
+				"return srv.Watch(in, runtime.ServerStream[Event]{Stream: stream})",
+				"out, err := srv.Get(stream.Context(), in)",
+			}
+			switch param {
+			case "grpc_web=true,connect=false":
+				// Get keeps its rpc default route for plain JSON requests, which shares its path with gRPC-Web.
+				wants = append(wants,
+					"func GetHandler(srv WatcherServer, opts ...runtime.ServerOption) (pattern string, hdr http.Handler) {",
+					`pattern = "POST /library.v1.Watcher/Get"`,
+					"pattern, rest := GetHandler(impl, opts...)",
+					"mux.Handle(o.CORS(pattern, runtime.GRPCWebOr(grpcWeb, rest)))",
+				)
+			case "grpc_web=true,connect=true":
+				wants = append(wants,
+					"mux.Handle(o.CORS(pattern, runtime.GRPCWebOr(grpcWeb, connect)))",
+					// The gRPC-Web route of Get is listed with the pattern of the Connect route serving it.
+					"Protocol:   runtime.ProtocolGRPCWeb,\n\t\t\tHTTPMethod: \"POST\",\n\t\t\tTemplate:   \"/library.v1.Watcher/Get\",\n\t\t\tPattern:    \"/library.v1.Watcher/Get\",",
+				)
+			default:
+				wants = append(wants, "mux.Handle(o.CORS(GetGRPCWebHandler(impl, opts...)))")
+			}
+			for _, want := range wants {
+				if !strings.Contains(code, want) {
+					t.Errorf("generated code does not contain %q:\n%s", want, code)
+				}
+			}
+			if strings.Contains(code, "Upload") {
+				t.Errorf("generated code serves the client-streaming Upload:\n%s", code)
+			}
+			if param == "grpc_web=true,connect=false" && strings.Contains(code, "mux.Handle(o.CORS(GetGRPCWebHandler(impl, opts...)))") {
+				t.Errorf("generated code registers the gRPC-Web handler of Get apart from its rpc default route:\n%s", code)
+			}
+		})
+	}
+}
+
+func TestPreflights(t *testing.T) {
+	const shelfProto = `
+syntax: "proto3"
+name: "library/v1/shelf.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: { name: "Shelf" field: { name: "shelf" number: 1 type: TYPE_STRING } field: { name: "book" number: 2 type: TYPE_STRING } }
+service: {
+	name: "Shelves"
+	method: {
+		name: "GetShelf"
+		input_type: ".library.v1.Shelf"
+		output_type: ".library.v1.Shelf"
+		options: { [google.api.http]: { get: "/v1/{shelf}" } }
+	}
+	method: {
+		name: "DeleteBook"
+		input_type: ".library.v1.Shelf"
+		output_type: ".library.v1.Shelf"
+		options: { [google.api.http]: { delete: "/v1/{book}" } }
+	}
+	method: {
+		name: "UpdateShelf"
+		input_type: ".library.v1.Shelf"
+		output_type: ".library.v1.Shelf"
+		options: { [google.api.http]: { patch: "/v1/{shelf}" body: "*" } }
+	}
+	method: {
+		name: "ListBooks"
+		input_type: ".library.v1.Shelf"
+		output_type: ".library.v1.Shelf"
+		options: { [google.api.http]: { get: "/v1/{shelf}/books" } }
+	}
+}
+`
+	gen := newTestPlugin(t, "connect=true", shelfProto)
+	pfs, err := preflights(gen.Files[len(gen.Files)-1].Services[0])
+	if err != nil {
+		t.Fatalf("preflights() failed with %v", err)
+	}
+	got := map[string][]string{}
+	for _, pf := range pfs {
+		got[pf.Path] = pf.Methods
+	}
+	want := map[string][]string{
+		"/v1/{shelf}":                     {"GET", "DELETE", "PATCH"},
+		"/v1/{shelf}/books":               {"GET"},
+		"/library.v1.Shelves/GetShelf":    {"POST"},
+		"/library.v1.Shelves/DeleteBook":  {"POST"},
+		"/library.v1.Shelves/UpdateShelf": {"POST"},
+		"/library.v1.Shelves/ListBooks":   {"POST"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("preflights() = %v, want %v", got, want)
+	}
+
+	if err := generateTestFiles(t, gen); err != nil {
+		t.Fatalf("generateFile() failed with %v", err)
+	}
+	code := generatedContent(t, gen, "example.com/library/v1/shelf_http.pb.go")
+	for _, want := range []string{
+		"o := runtime.NewServerOptions(opts...)",
+		"mux.Handle(o.CORS(GetShelfHandler(impl, opts...)))",
+		`o.HandlePreflight(mux, "/v1/{shelf}", "GET", "DELETE", "PATCH")`,
+		`o.HandlePreflight(mux, "/library.v1.Shelves/GetShelf", "POST")`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestGenerateTimeouts(t *testing.T) {
+	const slowProto = `
+syntax: "proto3"
+name: "library/v1/slow.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+dependency: "http_go/options.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: { name: "Empty" }
+service: {
+	name: "Slow"
+	method: {
+		name: "Export"
+		input_type: ".library.v1.Empty"
+		output_type: ".library.v1.Empty"
+		options: { [http_go.method]: { timeout: { seconds: 1 nanos: 500000000 } } }
+	}
+	method: { name: "Ping" input_type: ".library.v1.Empty" output_type: ".library.v1.Empty" }
+}
+`
+	gen := newTestPlugin(t, "twirp=true", slowProto)
+	if err := generateTestFiles(t, gen); err != nil {
+		t.Fatalf("generateFile() failed with %v", err)
+	}
+	code := generatedContent(t, gen, "example.com/library/v1/slow_http.pb.go")
+	if got := strings.Count(code, "o.CapTimeout(1500 * time.Millisecond)"); got != 2 {
+		t.Errorf("generated code caps the timeout %d times, want 2 for the REST and Twirp handlers of Export:\n%s", got, code)
+	}
+
+	gen = newTestPlugin(t, "", strings.Replace(slowProto, "seconds: 1 nanos: 500000000", "seconds: -1", 1))
+	err := generateTestFiles(t, gen)
+	if want := "library.v1.Slow.Export: timeout -1s is not a positive duration"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("generateFile() = %v, want an error containing %q", err, want)
+	}
+}
+
+func TestGenerateUpdateMask(t *testing.T) {
+	const updateProto = `
+syntax: "proto3"
+name: "library/v1/update.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+dependency: "google/protobuf/field_mask.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: {
+	name: "Book"
+	field: { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: {
+	name: "UpdateBookRequest"
+	field: { name: "id" number: 3 label: LABEL_OPTIONAL type: TYPE_STRING }
+	field: { name: "book" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".library.v1.Book" }
+	field: { name: "update_mask" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.FieldMask" }
+}
+service: {
+	name: "Updates"
+	method: {
+		name: "UpdateBook"
+		input_type: ".library.v1.UpdateBookRequest"
+		output_type: ".library.v1.Book"
+		options: { [google.api.http]: { patch: "/v1/books/{id}" body: "book" } }
+	}
+	method: {
+		name: "ReplaceBook"
+		input_type: ".library.v1.UpdateBookRequest"
+		output_type: ".library.v1.Book"
+		options: { [google.api.http]: { put: "/v1/books/{id}" body: "book" } }
+	}
+}
+`
+	gen := newTestPlugin(t, "", updateProto)
+	if warnings, err := lintRules(gen, true); len(warnings) > 0 || err != nil {
+		t.Fatalf("lintRules() = %v, %v", warnings, err)
+	}
+	if err := checkRoutes(gen); err != nil {
+		t.Fatalf("checkRoutes() failed with %v", err)
+	}
+	if err := generateTestFiles(t, gen); err != nil {
+		t.Fatalf("generateFile() failed with %v", err)
+	}
+	code := generatedContent(t, gen, "example.com/library/v1/update_http.pb.go")
+	// Only the PATCH infers its update mask, PUT replaces the whole resource.
+	if got := strings.Count(code, "err = o.DecodeQuery(r, in)\n\t\tif err != nil {\n\t\t\to.WriteError(ctx, w, err)\n\t\t\treturn\n\t\t}\n\t\terr = o.DecodeUpdateBody(r, in, \"book\")"); got != 1 {
+		t.Errorf("generated code infers the update mask in %d handlers, want 1:\n%s", got, code)
+	}
+	if want := `pattern = "PATCH /v1/books/{id}"`; !strings.Contains(code, want) {
+		t.Errorf("generated code does not contain %q:\n%s", want, code)
+	}
+
+	// The body of the PATCH is the book alone.
+	for _, f := range gen.Files {
+		if f.Generate {
+			if err := generateOpenAPI(gen, f); err != nil {
+				t.Fatalf("generateOpenAPI() failed with %v", err)
+			}
+		}
+	}
+	doc := generatedContent(t, gen, "example.com/library/v1/update.openapi.yaml")
+	patch := doc[strings.Index(doc, "patch:"):]
+	for _, want := range []string{
+		"name: update_mask.paths\n                  in: query",
+		"application/json:\n                        schema:\n                            $ref: '#/components/schemas/library.v1.Book'",
+	} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("generated document does not contain %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestGeneratePagination(t *testing.T) {
+	const listProto = `
+syntax: "proto3"
+name: "library/v1/list.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: {
+	name: "ListBooksRequest"
+	field: { name: "page_size" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 }
+	field: { name: "page_token" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: {
+	name: "ListBooksResponse"
+	field: { name: "books" number: 1 label: LABEL_REPEATED type: TYPE_STRING }
+	field: { name: "next_page_token" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: {
+	name: "ListAllBooksResponse"
+	field: { name: "books" number: 1 label: LABEL_REPEATED type: TYPE_STRING }
+}
+service: {
+	name: "Books"
+	method: {
+		name: "ListBooks"
+		input_type: ".library.v1.ListBooksRequest"
+		output_type: ".library.v1.ListBooksResponse"
+		options: { [google.api.http]: { get: "/v1/books" } }
+	}
+	method: {
+		name: "ListAllBooks"
+		input_type: ".library.v1.ListBooksRequest"
+		output_type: ".library.v1.ListAllBooksResponse"
+		options: { [google.api.http]: { get: "/v1/books:all" } }
+	}
+}
+`
+	gen := newTestPlugin(t, "connect=true", listProto)
+	if err := generateTestFiles(t, gen); err != nil {
+		t.Fatalf("generateFile() failed with %v", err)
+	}
+	code := generatedContent(t, gen, "example.com/library/v1/list_http.pb.go")
+	for _, want := range []string{
+		"func ListBooksIter(ctx context.Context, list func(context.Context, *ListBooksRequest) (*ListBooksResponse, error), in *ListBooksRequest) iter.Seq2[string, error] {",
+		"return runtime.Paginate(ctx, in, list, (*ListBooksResponse).GetBooks)",
+		"o.SetNextPage(w, r, out.GetNextPageToken())",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+	// ListAllBooks has no next_page_token, its page size is left alone by its REST and Connect handlers.
+	if got := strings.Count(code, "in.PageSize, err = o.PageSize(in.PageSize)"); got != 2 {
+		t.Errorf("generated code caps the page size in %d handlers, want 2 for the REST and Connect handlers of ListBooks:\n%s", got, code)
+	}
+	if strings.Contains(code, "ListAllBooksIter") {
+		t.Errorf("generated code has an iterator for ListAllBooks:\n%s", code)
+	}
+}
+
+func TestGenerateLongRunning(t *testing.T) {
+	const operationsProto = `
+syntax: "proto3"
+name: "google/longrunning/operations.proto"
+package: "google.longrunning"
+options: { go_package: "cloud.google.com/go/longrunning/autogen/longrunningpb;longrunningpb" }
+message_type: {
+	name: "Operation"
+	field: { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+	field: { name: "done" number: 3 label: LABEL_OPTIONAL type: TYPE_BOOL }
+}
+message_type: {
+	name: "GetOperationRequest"
+	field: { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+`
+	const exportProto = `
+syntax: "proto3"
+name: "library/v1/export.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+dependency: "google/longrunning/operations.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: { name: "ExportRequest" }
+message_type: { name: "ExportResponse" }
+service: {
+	name: "Books"
+	method: {
+		name: "Export"
+		input_type: ".library.v1.ExportRequest"
+		output_type: ".google.longrunning.Operation"
+		options: { [google.api.http]: { post: "/v1/books:export" body: "*" } }
+	}
+}
+`
+	setOperationInfo := func(method *protogen.Method, responseType string) {
+		// The plugin does not link google/longrunning/operations.proto, the operation_info option is set unknown.
+		info := protowire.AppendTag(nil, 1, protowire.BytesType)
+		info = protowire.AppendString(info, responseType)
+		opts := protowire.AppendTag(nil, operationInfoField, protowire.BytesType)
+		opts = protowire.AppendBytes(opts, info)
+		method.Desc.Options().ProtoReflect().SetUnknown(opts)
+	}
+	for _, spec := range []struct {
+		responseType string
+		wantErr      string
+	}{
+		{responseType: "ExportResponse"},
+		{responseType: "library.v1.ExportResponse"},
+		{responseType: "ImportResponse", wantErr: `response_type "ImportResponse" is not a known message`},
+	} {
+		gen := newTestPlugin(t, "", operationsProto, exportProto)
+		setOperationInfo(gen.FilesByPath["library/v1/export.proto"].Services[0].Methods[0], spec.responseType)
+
+		err := generateTestFiles(t, gen)
+		if spec.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), spec.wantErr) {
+				t.Errorf("generateFile() with the response_type %q failed with %v, want %q", spec.responseType, err, spec.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("generateFile() with the response_type %q failed with %v", spec.responseType, err)
+		}
+		code := generatedContent(t, gen, "example.com/library/v1/export_http.pb.go")
+		for _, want := range []string{
+			"o.SetOperationLocation(w, out.GetName())",
+			"o.WriteResponse(ctx, w, http.StatusAccepted, out)",
+			"func ExportWait(ctx context.Context, get func(context.Context, *longrunningpb.GetOperationRequest) (*longrunningpb.Operation, error), op *longrunningpb.Operation) (*ExportResponse, error) {",
+			"func BooksOperationsHandler(ops interface {",
+			`pattern = "GET /operations/{name...}"`,
+			`in := &longrunningpb.GetOperationRequest{Name: r.PathValue("name")}`,
+			"ops, ok := impl.(interface {",
+			"pattern, hdr := BooksOperationsHandler(ops, opts...)",
+			"o.HandleOperations(mux, pattern, hdr)",
+			`FullMethod: "/google.longrunning.Operations/GetOperation",`,
+			"Input:      (*longrunningpb.GetOperationRequest)(nil).ProtoReflect().Descriptor(),",
+		} {
+			if !strings.Contains(code, want) {
+				t.Errorf("generated code does not contain %q:\n%s", want, code)
+			}
+		}
+	}
+
+	// The services share the operations route, which is checked against the other routes.
+	const archiveProto = `
+syntax: "proto3"
+name: "library/v1/archive.proto"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+dependency: "google/longrunning/operations.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: { name: "ArchiveRequest" }
+service: {
+	name: "Archives"
+	method: {
+		name: "Archive"
+		input_type: ".library.v1.ArchiveRequest"
+		output_type: ".google.longrunning.Operation"
+		options: { [google.api.http]: { post: "/v1/books:archive" body: "*" } }
+	}
+	method: {
+		name: "GetArchive"
+		input_type: ".library.v1.ArchiveRequest"
+		output_type: ".library.v1.ArchiveRequest"
+		options: { [google.api.http]: { get: "%s" } }
+	}
+}
+`
+	for path, want := range map[string]string{
+		"/v1/archives/{id}": "",
+		"/{kind}/{id}":      `route "GET /{kind}/{id}" conflicts with route "GET /operations/{name...}" of library.v1.Books.Export`,
+	} {
+		gen := newTestPlugin(t, "", operationsProto, exportProto, fmt.Sprintf(archiveProto, path))
+		setOperationInfo(gen.FilesByPath["library/v1/export.proto"].Services[0].Methods[0], "ExportResponse")
+		setOperationInfo(gen.FilesByPath["library/v1/archive.proto"].Services[0].Methods[0], "ArchiveRequest")
+		err := checkRoutes(gen)
+		if want == "" && err != nil {
+			t.Errorf("checkRoutes() with the route %q failed with %v", path, err)
+		}
+		if want != "" && (err == nil || !strings.Contains(err.Error(), want)) {
+			t.Errorf("checkRoutes() with the route %q failed with %v, want %q", path, err, want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -18,6 +19,24 @@ func toCamelCase(str string) string {
 	})
 }
 
+// toKebabCase converts a CamelCase name to kebab case, e.g. "GetHTTPBody" to "get-http-body".
+func toKebabCase(str string) string {
+	runes := []rune(str)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) && runes[i-1] != '_' {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		} else if r == '_' {
+			r = '-'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 type pathParam struct {
 	Index  int
 	Name   string
@@ -71,6 +90,97 @@ func parsePathParam(pattern string) ([]*pathParam, error) {
 	return params, nil
 }
 
+// isHttpBody reports whether msg is google.api.HttpBody, whose data is carried as the raw HTTP body.
+func isHttpBody(msg *protogen.Message) bool {
+	return msg != nil && msg.Desc.FullName() == "google.api.HttpBody"
+}
+
+// isUpdateMaskInferred reports whether the update mask of the route is inferred from its body: the route is a PATCH
+// whose body is a message field of a request with an update_mask google.protobuf.FieldMask field, see AIP-134.
+func isUpdateMaskInferred(rt *route) bool {
+	if rt.HTTPMethod != "PATCH" || rt.BodyField == nil || rt.BodyField.Message == nil || isHttpBody(rt.BodyField.Message) {
+		return false
+	}
+	mask := findField(rt.Method.Input, "update_mask")
+	return mask != nil && mask.Message != nil && mask.Message.Desc.FullName() == "google.protobuf.FieldMask"
+}
+
+// pageItems returns the repeated field holding the items of a page in the response of m when m is paginated
+// like AIP-158 asks: its request has an int32 page_size and a string page_token, and its response a string
+// next_page_token and a repeated field, the first one. It returns nil otherwise.
+func pageItems(m *protogen.Method) *protogen.Field {
+	if !isUnary(m) {
+		return nil
+	}
+	pageSize, pageToken, next := findField(m.Input, "page_size"), findField(m.Input, "page_token"), findField(m.Output, "next_page_token")
+	if !isSingular(pageSize, protoreflect.Int32Kind) || !isSingular(pageToken, protoreflect.StringKind) || !isSingular(next, protoreflect.StringKind) {
+		return nil
+	}
+	for _, field := range m.Output.Fields {
+		if field.Desc.IsList() {
+			return field
+		}
+	}
+	return nil
+}
+
+func isSingular(field *protogen.Field, kind protoreflect.Kind) bool {
+	return field != nil && field.Desc.Kind() == kind && !field.Desc.IsList()
+}
+
+// goType returns the Go type of an element of the repeated field.
+func goType(g *protogen.GeneratedFile, field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "*" + g.QualifiedGoIdent(field.Message.GoIdent)
+	case protoreflect.EnumKind:
+		return g.QualifiedGoIdent(field.Enum.GoIdent)
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.BytesKind:
+		return "[]byte"
+	}
+	return "string"
+}
+
+// findField returns the field of msg with the proto name, or nil.
+func findField(msg *protogen.Message, name string) *protogen.Field {
+	for _, field := range msg.Fields {
+		if string(field.Desc.Name()) == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// findFieldPath returns the field of msg addressed by a dotted path such as "book.name", or nil.
+func findFieldPath(msg *protogen.Message, fieldPath string) *protogen.Field {
+	var field *protogen.Field
+	for _, name := range strings.Split(fieldPath, ".") {
+		if msg == nil {
+			return nil
+		}
+		field = findField(msg, name)
+		if field == nil {
+			return nil
+		}
+		msg = field.Message
+	}
+	return field
+}
+
 type queryParam struct {
 	*protogen.Field
 
@@ -106,3 +216,14 @@ func createQueryParams(method *protogen.Method) []*queryParam {
 
 	return queryParams
 }
+
+// sourcePos returns the position of desc in its proto file as "file.proto:line:col",
+// or just the file name when the file has no source info.
+func sourcePos(desc protoreflect.Descriptor) string {
+	file := desc.ParentFile()
+	loc := file.SourceLocations().ByDescriptor(desc)
+	if loc.Path == nil {
+		return file.Path()
+	}
+	return fmt.Sprintf("%s:%d:%d", file.Path(), loc.StartLine+1, loc.StartColumn+1)
+}
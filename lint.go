@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Field numbers of google.api.HttpRule and of the google.api.http method option,
+// used to locate a diagnostic as precisely as the source info of the file allows.
+const (
+	httpOptionNumber           = 72295728
+	methodOptionsNumber        = 4
+	httpRuleBodyNumber         = 7
+	httpRuleResponseBodyNumber = 12
+	httpRuleAdditionalNumber   = 11
+)
+
+// diagnostic is a problem found in the google.api.http rule of a method.
+type diagnostic struct {
+	Pos     string
+	Message string
+	Warning bool
+}
+
+func (d diagnostic) String() string {
+	if d.Warning {
+		return d.Pos + ": warning: " + d.Message
+	}
+	return d.Pos + ": " + d.Message
+}
+
+// lintRules checks the google.api.http rules of the methods to generate against their input and output messages.
+// It returns the warnings, and an error listing every error, and the warnings too when warningsAsErrors is set.
+func lintRules(gen *protogen.Plugin, warningsAsErrors bool) ([]diagnostic, error) {
+	var warnings []diagnostic
+	var errs []error
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		for _, s := range f.Services {
+			for _, m := range s.Methods {
+				if !isServed(m) || !isUnary(m) {
+					continue
+				}
+				for _, d := range lintMethod(m) {
+					if d.Warning && !warningsAsErrors {
+						warnings = append(warnings, d)
+						continue
+					}
+					errs = append(errs, errors.New(d.String()))
+				}
+			}
+		}
+	}
+	return warnings, errors.Join(errs...)
+}
+
+// lintMethod returns the diagnostics of the google.api.http rule of m, if any.
+func lintMethod(m *protogen.Method) []diagnostic {
+	rule := httpRule(m)
+	if rule == nil {
+		return nil
+	}
+
+	var diags []diagnostic
+	report := func(field int32, warning bool, format string, args ...any) {
+		diags = append(diags, diagnostic{
+			Pos:     rulePos(m, field),
+			Message: fmt.Sprintf("%s: ", m.Desc.FullName()) + fmt.Sprintf(format, args...),
+			Warning: warning,
+		})
+	}
+
+	if rule.Pattern == nil {
+		report(0, false, "google.api.http rule has no get, put, post, delete, patch or custom pattern")
+		return diags
+	}
+	method, path := buildHTTPRule(m, rule)
+	patternField := patternFieldNumber(rule)
+	if c, ok := rule.Pattern.(*annotations.HttpRule_Custom); ok && c.Custom.GetKind() == "" {
+		report(patternField, false, "custom pattern has no kind")
+	}
+
+	switch {
+	case path == "":
+		report(patternField, false, "%s pattern has an empty path", method)
+	case !strings.HasPrefix(path, "/"):
+		report(patternField, false, "path %q does not start with /", path)
+	default:
+		params, err := parsePathParam(path)
+		if err != nil {
+			report(patternField, false, "invalid path %q: %v", path, err)
+			break
+		}
+		seen := map[string]bool{}
+		for _, p := range params {
+			if seen[p.Name] {
+				report(patternField, false, "path variable %q is bound more than once", p.Name)
+				continue
+			}
+			seen[p.Name] = true
+			if i := strings.Index(path, "{"+p.Name+"="); i >= 0 {
+				template, _, _ := strings.Cut(path[i+len(p.Name)+2:], "}")
+				report(patternField, false, "path variable %q has the segment pattern %q, which http.ServeMux cannot route; use {%s} alone", p.Name, template, p.Name)
+				continue
+			}
+			if msg := lintPathVariable(m.Input, p.Name); msg != "" {
+				report(patternField, false, "path variable %q %s", p.Name, msg)
+			}
+			if rule.Body != "" && rule.Body != "*" && strings.SplitN(p.Name, ".", 2)[0] == rule.Body {
+				report(httpRuleBodyNumber, true, "path variable %q is also bound by body %q", p.Name, rule.Body)
+			}
+		}
+	}
+
+	if rule.Body != "" {
+		switch method {
+		case "GET":
+			report(httpRuleBodyNumber, false, "GET rule must not have a body")
+		case "DELETE":
+			report(httpRuleBodyNumber, true, "DELETE rule has a body, which many clients and proxies drop")
+		}
+		if rule.Body != "*" && findField(m.Input, rule.Body) == nil {
+			report(httpRuleBodyNumber, false, "body %q is not a top-level field of %s", rule.Body, m.Input.Desc.FullName())
+		}
+	}
+
+	if rule.ResponseBody != "" && findField(m.Output, rule.ResponseBody) == nil {
+		report(httpRuleResponseBodyNumber, false, "response_body %q is not a top-level field of %s", rule.ResponseBody, m.Output.Desc.FullName())
+	}
+
+	if len(rule.AdditionalBindings) > 0 {
+		report(httpRuleAdditionalNumber, true, "additional_bindings are not supported and are ignored")
+	}
+	return diags
+}
+
+// lintPathVariable returns why the field path of a path variable cannot be bound in msg, or "" if it can.
+func lintPathVariable(msg *protogen.Message, fieldPath string) string {
+	if strings.Contains(fieldPath, ".") {
+		if findFieldPath(msg, fieldPath) == nil {
+			return fmt.Sprintf("is not a field of %s", msg.Desc.FullName())
+		}
+		return "is a nested field, which is not supported"
+	}
+	field := findField(msg, fieldPath)
+	switch {
+	case field == nil:
+		return fmt.Sprintf("is not a field of %s", msg.Desc.FullName())
+	case field.Desc.IsMap():
+		return "is a map field"
+	case field.Desc.IsList():
+		return "is a repeated field"
+	case field.Desc.Kind() == protoreflect.MessageKind || field.Desc.Kind() == protoreflect.GroupKind:
+		return "is a message field"
+	case field.Desc.Kind() != protoreflect.StringKind:
+		return fmt.Sprintf("is a %s field, only string fields are supported", field.Desc.Kind())
+	case field.Desc.HasPresence():
+		return "is an optional field, which is not supported"
+	}
+	return ""
+}
+
+// patternFieldNumber returns the field number of the pattern set in rule.
+func patternFieldNumber(rule *annotations.HttpRule) int32 {
+	var name protoreflect.Name
+	switch rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		name = "get"
+	case *annotations.HttpRule_Put:
+		name = "put"
+	case *annotations.HttpRule_Post:
+		name = "post"
+	case *annotations.HttpRule_Delete:
+		name = "delete"
+	case *annotations.HttpRule_Patch:
+		name = "patch"
+	case *annotations.HttpRule_Custom:
+		name = "custom"
+	default:
+		return 0
+	}
+	return int32(rule.ProtoReflect().Descriptor().Fields().ByName(name).Number())
+}
+
+// rulePos returns the position of the field of the google.api.http rule of m, falling back to the rule
+// and then to the method when the source info of the file does not go that deep.
+func rulePos(m *protogen.Method, field int32) string {
+	file := m.Desc.ParentFile()
+	path := append(protoreflect.SourcePath{}, m.Location.Path...)
+	path = append(path, methodOptionsNumber, httpOptionNumber)
+	if field != 0 {
+		if loc := file.SourceLocations().ByPath(append(path, field)); loc.Path != nil {
+			return fmt.Sprintf("%s:%d:%d", file.Path(), loc.StartLine+1, loc.StartColumn+1)
+		}
+	}
+	if loc := file.SourceLocations().ByPath(path); loc.Path != nil {
+		return fmt.Sprintf("%s:%d:%d", file.Path(), loc.StartLine+1, loc.StartColumn+1)
+	}
+	return sourcePos(m.Desc)
+}
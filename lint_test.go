@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintRules(t *testing.T) {
+	const proto = `
+name: "shop/v1/shop.proto"
+syntax: "proto3"
+package: "shop.v1"
+dependency: "google/api/annotations.proto"
+options: { go_package: "example.com/shop/v1;shopv1" }
+message_type: {
+	name: "Item"
+	field: { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+	field: { name: "id" number: 2 label: LABEL_OPTIONAL type: TYPE_INT64 }
+	field: { name: "tags" number: 3 label: LABEL_REPEATED type: TYPE_STRING }
+	field: { name: "owner" number: 4 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".shop.v1.Item" }
+}
+service: {
+	name: "Shop"
+	method: {
+		name: "Method"
+		input_type: ".shop.v1.Item"
+		output_type: ".shop.v1.Item"
+		options: { [google.api.http]: { RULE } }
+	}
+}
+source_code_info: {
+	location: { path: [6, 0, 2, 0] span: [10, 2, 12, 3] }
+	location: { path: [6, 0, 2, 0, 4, 72295728] span: [11, 4, 40] }
+	location: { path: [6, 0, 2, 0, 4, 72295728, 7] span: [11, 30, 38] }
+}
+`
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{rule: `get: "/v1/{name}"`},
+		{rule: `post: "/v1/items" body: "*"`},
+		{rule: `get: "/v1/items" body: "*"`, want: []string{"shop/v1/shop.proto:12:31: shop.v1.Shop.Method: GET rule must not have a body"}},
+		{rule: `post: "/v1/items" body: "item"`, want: []string{`shop/v1/shop.proto:12:31: shop.v1.Shop.Method: body "item" is not a top-level field of shop.v1.Item`}},
+		{rule: `patch: "/v1/items" response_body: "owner.name"`, want: []string{`shop/v1/shop.proto:12:5: shop.v1.Shop.Method: response_body "owner.name" is not a top-level field of shop.v1.Item`}},
+		{rule: `get: "/v1/{tags}"`, want: []string{`path variable "tags" is a repeated field`}},
+		{rule: `get: "/v1/{owner}"`, want: []string{`path variable "owner" is a message field`}},
+		{rule: `get: "/v1/{id}"`, want: []string{`path variable "id" is a int64 field, only string fields are supported`}},
+		{rule: `get: "/v1/{title}"`, want: []string{`path variable "title" is not a field of shop.v1.Item`}},
+		{rule: `get: "/v1/{name=items/*}"`, want: []string{`shop/v1/shop.proto:12:5: shop.v1.Shop.Method: path variable "name" has the segment pattern "items/*", which http.ServeMux cannot route`}},
+		{rule: `get: "/v1/{name=*}"`, want: []string{`path variable "name" has the segment pattern "*"`}},
+		{rule: `get: "/v1/{name}/{name}"`, want: []string{`path variable "name" is bound more than once`}},
+		{rule: `get: "v1/items"`, want: []string{`path "v1/items" does not start with /`}},
+		{rule: `get: "/v1/{name"`, want: []string{`invalid path "/v1/{name"`}},
+		{rule: `custom: { kind: "HEAD" }`, want: []string{"HEAD pattern has an empty path"}},
+		{rule: `custom: { path: "/v1/items" }`, want: []string{"custom pattern has no kind"}},
+		{rule: `body: "*"`, want: []string{"shop/v1/shop.proto:12:5: shop.v1.Shop.Method: google.api.http rule has no get, put, post, delete, patch or custom pattern"}},
+		{rule: `delete: "/v1/{name}" body: "*"`, want: []string{"shop/v1/shop.proto:12:31: warning: shop.v1.Shop.Method: DELETE rule has a body"}},
+		{rule: `put: "/v1/{name}" body: "name"`, want: []string{`warning: shop.v1.Shop.Method: path variable "name" is also bound by body "name"`}},
+		{rule: `get: "/v1/items" additional_bindings: { get: "/v2/items" }`, want: []string{"warning: shop.v1.Shop.Method: additional_bindings are not supported and are ignored"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			gen := newTestPlugin(t, "", strings.Replace(proto, "RULE", tt.rule, 1))
+			warnings, err := lintRules(gen, false)
+			var got []string
+			for _, w := range warnings {
+				got = append(got, w.String())
+			}
+			if err != nil {
+				got = append(got, strings.Split(err.Error(), "\n")...)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("lintRules() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("lintRules()[%d] = %q, want it to contain %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLintRulesWarningsAsErrors(t *testing.T) {
+	gen := newTestPlugin(t, "", libraryProto)
+	warnings, err := lintRules(gen, false)
+	if err != nil || len(warnings) != 1 {
+		t.Fatalf("lintRules() = %v, %v, want one warning", warnings, err)
+	}
+	warnings, err = lintRules(gen, true)
+	if err == nil || len(warnings) != 0 {
+		t.Fatalf("lintRules() = %v, %v, want the warning as an error", warnings, err)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// operationInfoField is the field number of the google.longrunning.operation_info method option.
+const operationInfoField = 1049
+
+// isLongRunning reports whether m is a long-running method, see AIP-151: it returns a google.longrunning.Operation
+// and has the operation_info option. Methods of the Operations service also return operations, without the option.
+func isLongRunning(m *protogen.Method) bool {
+	_, ok := operationInfo(m)
+	return ok && isUnary(m) && m.Output.Desc.FullName() == "google.longrunning.Operation"
+}
+
+// operationInfo returns the response_type of the operation_info option of m.
+// The option is read from the encoded options, as the plugin does not link the longrunning package.
+func operationInfo(m *protogen.Method) (responseType string, ok bool) {
+	b, err := proto.Marshal(m.Desc.Options())
+	if err != nil {
+		return "", false
+	}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return "", false
+		}
+		b = b[n:]
+		if num == operationInfoField && typ == protowire.BytesType {
+			info, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return "", false
+			}
+			b = b[n:]
+			ok = true
+			for len(info) > 0 {
+				num, typ, n := protowire.ConsumeTag(info)
+				if n < 0 {
+					return "", false
+				}
+				info = info[n:]
+				if num == 1 && typ == protowire.BytesType {
+					v, n := protowire.ConsumeBytes(info)
+					if n < 0 {
+						return "", false
+					}
+					responseType = string(v)
+					info = info[n:]
+					continue
+				}
+				if n = protowire.ConsumeFieldValue(num, typ, info); n < 0 {
+					return "", false
+				}
+				info = info[n:]
+			}
+			continue
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+			return "", false
+		}
+		b = b[n:]
+	}
+	return responseType, ok
+}
+
+// operationResponse returns the message of the response_type of the long-running method m,
+// looked up in the package of m when it is not fully qualified.
+func operationResponse(gen *protogen.Plugin, m *protogen.Method) (*protogen.Message, error) {
+	responseType, _ := operationInfo(m)
+	if responseType == "" {
+		return nil, fmt.Errorf("%s: %s: operation_info has no response_type", sourcePos(m.Desc), m.Desc.FullName())
+	}
+	name := responseType
+	if pkg := string(m.Desc.ParentFile().Package()); pkg != "" && !strings.Contains(responseType, ".") {
+		name = pkg + "." + responseType
+	}
+	for _, f := range gen.Files {
+		for _, msg := range allMessages(f.Messages) {
+			if string(msg.Desc.FullName()) == name {
+				return msg, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: %s: operation_info response_type %q is not a known message", sourcePos(m.Desc), m.Desc.FullName(), responseType)
+}
+
+// allMessages returns msgs and their nested messages.
+func allMessages(msgs []*protogen.Message) []*protogen.Message {
+	var all []*protogen.Message
+	for _, msg := range msgs {
+		all = append(all, msg)
+		all = append(all, allMessages(msg.Messages)...)
+	}
+	return all
+}
+
+// operationsPath is the path of the route of the XxxOperationsHandler, where SetOperationLocation points.
+const operationsPath = "/operations/{name...}"
+
+// operationsRoute returns the route of the XxxOperationsHandler of s, or nil when s has no long-running method.
+// Its Method is the first long-running method of s, which conflicts with the route are reported against.
+func operationsRoute(s *protogen.Service) *route {
+	for _, m := range servedMethods(s) {
+		if isLongRunning(m) {
+			return &route{Method: m, Protocol: protocolREST, HTTPMethod: http.MethodGet, Path: operationsPath}
+		}
+	}
+	return nil
+}
+
+// operationRequest returns the google.longrunning.GetOperationRequest message, defined along op.
+func operationRequest(op *protogen.Message) protogen.GoIdent {
+	return protogen.GoIdent{GoName: "GetOperationRequest", GoImportPath: op.GoIdent.GoImportPath}
+}
+
+// genOperationsHandler generates the XxxOperationsHandler serving GET /operations/{name...}, the Location of the
+// responses of the long-running methods of s, with the GetOperation method of a google.longrunning.Operations server.
+// The operation name is the path after /operations/, as set by SetOperationLocation.
+// op is the google.longrunning.Operation message, GetOperationRequest is defined along.
+func genOperationsHandler(g *protogen.GeneratedFile, s *protogen.Service, op *protogen.Message) {
+	getOperationRequest := operationRequest(op)
+	const fullMethod = "/google.longrunning.Operations/GetOperation"
+	g.P("// ", s.GoName, "OperationsHandler returns the handler of GET /operations/{name...}, where the Location of the 202 responses")
+	g.P("// of ", s.GoName, "'s long-running methods points, getting the operations from ops, e.g. a google.longrunning.Operations server.")
+	g.P("// RegisterHttpServer registers it with impl once per ServeMux, see HandleOperations.")
+	g.P("func ", s.GoName, "OperationsHandler(ops interface {")
+	g.P("    GetOperation(", contextPackage.Ident("Context"), ", *", getOperationRequest, ") (*", op.GoIdent, ", error)")
+	g.P("}, opts ...", runtimePackage.Ident("ServerOption"), ") (pattern string, hdr ", httpPackage.Ident("Handler"), ") {")
+	g.P("    o := ", runtimePackage.Ident("NewServerOptions"), "(opts...)")
+	g.P("    pattern = ", strconv.Quote(http.MethodGet+" "+operationsPath))
+	g.P("    hdr = o.Instrument(", strconv.Quote(fullMethod), ", ", strconv.Quote(operationsPath), ", ", httpPackage.Ident("HandlerFunc"), "(func(w ", httpPackage.Ident("ResponseWriter"), ", r *", httpPackage.Ident("Request"), ") {")
+	g.P("        ctx := o.NewContext(r)")
+	g.P("        in := &", getOperationRequest, "{Name: r.PathValue(\"name\")}")
+	g.P("        o.Decoded(ctx, in)")
+	g.P("        var out *", op.GoIdent)
+	g.P("        var err error")
+	g.P("        func() {")
+	g.P("            defer o.Recover(r, ", strconv.Quote(fullMethod), ", &err)")
+	g.P("            out, err = ops.GetOperation(ctx, in)")
+	g.P("        }()")
+	g.P("        if err != nil {")
+	g.P("            o.WriteError(ctx, w, err)")
+	g.P("            return")
+	g.P("        }")
+	g.P("        o.WriteResponse(ctx, w, ", httpPackage.Ident("StatusOK"), ", out)")
+	g.P("    }))")
+	g.P("    return")
+	g.P("}")
+	g.P()
+}
+
+// genOperationWait generates the XxxWait function polling the operation of the long-running method m until it is
+// done and returning its response, a resp message.
+func genOperationWait(g *protogen.GeneratedFile, m *protogen.Method, resp *protogen.Message) {
+	getOperationRequest := operationRequest(m.Output)
+	g.P("// ", m.GoName, "Wait polls op, the operation returned by ", m.Parent.GoName, "'s ", m.GoName, ", with get and an exponential backoff")
+	g.P("// until it is done, e.g. with a client's GetOperation method. It returns the ", resp.GoIdent.GoName, " of the operation, or its error.")
+	g.P("func ", m.GoName, "Wait(ctx ", contextPackage.Ident("Context"), ", get func(", contextPackage.Ident("Context"), ", *", getOperationRequest, ") (*", m.Output.GoIdent, ", error), op *", m.Output.GoIdent, ") (*", resp.GoIdent, ", error) {")
+	g.P("    out := &", resp.GoIdent, "{}")
+	g.P("    err := ", runtimePackage.Ident("WaitOperation"), "(ctx, op, func(ctx ", contextPackage.Ident("Context"), ", name string) (*", m.Output.GoIdent, ", error) {")
+	g.P("        return get(ctx, &", getOperationRequest, "{Name: name})")
+	g.P("    }, out)")
+	g.P("    if err != nil {")
+	g.P("        return nil, err")
+	g.P("    }")
+	g.P("    return out, nil")
+	g.P("}")
+	g.P()
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/types/pluginpb"
@@ -10,6 +11,21 @@ import (
 
 const version = "0.0.1"
 
+var (
+	flags flag.FlagSet
+
+	genHandlers        = flags.Bool("handlers", true, "generate the _http.pb.go handlers")
+	serveOpenAPI       = flags.Bool("serve_openapi", false, "embed the OpenAPI document in the handlers so RegisterHttpServer can serve it")
+	openAPIMode        = flags.String("openapi", "", `generate OpenAPI v3 documents: "file" for one per proto file, "service" for one per service`)
+	openAPIVersion     = flags.String("openapi_version", "0.0.1", "version of the API written in the OpenAPI documents")
+	openAPIErrorSchema = flags.String("openapi_error_schema", "", "full name of the message documented as the error response, defaults to the runtime error envelope")
+	defaultRoutes      = flags.String("default_routes", "rpc", `route of the methods without google.api.http rule: "rpc" for POST /package.Service/Method, "rest" for POST /package/service/method in kebab case, "none" to not serve them`)
+	connect            = flags.Bool("connect", false, "also serve the methods with the Connect unary protocol at POST /package.Service/Method")
+	twirp              = flags.Bool("twirp", false, "also serve the methods with the Twirp protocol at POST /twirp/package.Service/Method")
+	grpcWeb            = flags.Bool("grpc_web", false, "also serve the unary and server-streaming methods with the gRPC-Web protocol at POST /package.Service/Method")
+	warningsAsErrors   = flags.Bool("warnings_as_errors", false, "fail generation on google.api.http rule warnings too")
+)
+
 func main() {
 	showVersion := flag.Bool("version", false, "print the version and exit")
 	flag.Parse()
@@ -18,21 +34,49 @@ func main() {
 		return
 	}
 
-	var flags flag.FlagSet
-
 	options := protogen.Options{
 		ParamFunc: flags.Set,
 	}
 
 	options.Run(func(gen *protogen.Plugin) error {
 		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		switch *openAPIMode {
+		case "", "file", "service":
+		default:
+			return fmt.Errorf("invalid openapi option %q, must be file or service", *openAPIMode)
+		}
+		switch *defaultRoutes {
+		case "rpc", "rest", "none":
+		default:
+			return fmt.Errorf("invalid default_routes option %q, must be rpc, rest or none", *defaultRoutes)
+		}
+		warnings, err := lintRules(gen, *warningsAsErrors)
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, w)
+		}
+		if err != nil {
+			return err
+		}
+		if *genHandlers {
+			if err := checkRoutes(gen); err != nil {
+				return err
+			}
+		}
 		for _, f := range gen.Files {
 			if !f.Generate {
 				continue
 			}
-			err := generateFile(gen, f)
-			if err != nil {
-				return err
+			if *genHandlers {
+				err := generateFile(gen, f)
+				if err != nil {
+					return err
+				}
+			}
+			if *openAPIMode != "" {
+				err := generateOpenAPI(gen, f)
+				if err != nil {
+					return err
+				}
 			}
 		}
 		return nil
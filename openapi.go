@@ -0,0 +1,501 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	v3 "github.com/google/gnostic-models/openapiv3"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxQueryDepth bounds how deep nested message fields are expanded into query parameters,
+// recursive messages would expand forever otherwise.
+const maxQueryDepth = 3
+
+var pathVariableRe = regexp.MustCompile(`{([^=}]+)(=[^}]*)?}`)
+
+// generateOpenAPI generates the .openapi.yaml documents of file.
+// The documents describe the routes, bodies and status codes of the generated handlers,
+// so they are built from the same routes and follow the encoding/json representation the handlers use.
+func generateOpenAPI(gen *protogen.Plugin, file *protogen.File) error {
+	services := servedServices(file)
+	if len(services) == 0 {
+		return nil
+	}
+	if *openAPIMode == "service" {
+		for _, s := range services {
+			filename := path.Join(path.Dir(file.GeneratedFilenamePrefix), s.GoName+".openapi.yaml")
+			if err := writeOpenAPI(gen, file, []*protogen.Service{s}, filename); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return writeOpenAPI(gen, file, services, file.GeneratedFilenamePrefix+".openapi.yaml")
+}
+
+func writeOpenAPI(gen *protogen.Plugin, file *protogen.File, services []*protogen.Service, filename string) error {
+	b := &openAPIBuilder{gen: gen, seen: map[protoreflect.FullName]bool{}}
+	d, err := b.document(file, services)
+	if err != nil {
+		return err
+	}
+	content, err := d.YAMLValue("Code generated by protoc-gen-http-go. DO NOT EDIT.")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %v", filename, err)
+	}
+	g := gen.NewGeneratedFile(filename, "")
+	_, err = g.Write(content)
+	return err
+}
+
+// generateOpenAPIJSON generates the .openapi.json document of file, which the handlers embed to serve it.
+func generateOpenAPIJSON(gen *protogen.Plugin, file *protogen.File) error {
+	filename := file.GeneratedFilenamePrefix + ".openapi.json"
+	b := &openAPIBuilder{gen: gen, seen: map[protoreflect.FullName]bool{}}
+	d, err := b.document(file, servedServices(file))
+	if err != nil {
+		return err
+	}
+	var v any
+	if err := d.ToRawInfo().Decode(&v); err != nil {
+		return fmt.Errorf("marshal %s: %v", filename, err)
+	}
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %v", filename, err)
+	}
+	g := gen.NewGeneratedFile(filename, "")
+	_, err = g.Write(append(content, '\n'))
+	return err
+}
+
+// openAPISpecVar returns the name of the variable embedding the .openapi.json document of file.
+func openAPISpecVar(file *protogen.File) string {
+	return "file_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, file.Desc.Path()) + "_openapi"
+}
+
+// openAPIBuilder builds an OpenAPI v3 document, collecting the schemas of the messages it references.
+type openAPIBuilder struct {
+	gen     *protogen.Plugin
+	seen    map[protoreflect.FullName]bool
+	pending []*protogen.Message
+}
+
+func (b *openAPIBuilder) document(file *protogen.File, services []*protogen.Service) (*v3.Document, error) {
+	title := string(file.Desc.Package()) + " API"
+	if len(services) == 1 {
+		title = services[0].GoName + " API"
+	}
+	d := &v3.Document{
+		Openapi: "3.0.3",
+		Info: &v3.Info{
+			Title:   title,
+			Version: *openAPIVersion,
+		},
+		Paths: &v3.Paths{},
+		Components: &v3.Components{
+			Schemas: &v3.SchemasOrReferences{},
+		},
+	}
+	if ext, ok := proto.GetExtension(file.Desc.Options(), v3.E_Document).(*v3.Document); ok && ext != nil {
+		proto.Merge(d, ext)
+	}
+
+	errRef, err := b.errorSchema(d)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range services {
+		d.Tags = append(d.Tags, &v3.Tag{
+			Name:        s.GoName,
+			Description: comment(s.Comments.Leading),
+		})
+		routes, err := buildRoutes(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, rt := range routes {
+			b.addOperation(d, rt, errRef)
+		}
+	}
+
+	for len(b.pending) > 0 {
+		msg := b.pending[0]
+		b.pending = b.pending[1:]
+		d.Components.Schemas.AdditionalProperties = append(d.Components.Schemas.AdditionalProperties, &v3.NamedSchemaOrReference{
+			Name:  string(msg.Desc.FullName()),
+			Value: b.messageSchema(msg),
+		})
+	}
+	schemas := d.Components.Schemas.AdditionalProperties
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+	return d, nil
+}
+
+// errorSchema returns the schema of error responses.
+// It is the envelope written by runtime.WriteError unless the openapi_error_schema option names a message.
+func (b *openAPIBuilder) errorSchema(d *v3.Document) (*v3.SchemaOrReference, error) {
+	if *openAPIErrorSchema != "" {
+		msg := b.findMessage(protoreflect.FullName(*openAPIErrorSchema))
+		if msg == nil {
+			return nil, fmt.Errorf("openapi_error_schema: message %q not found", *openAPIErrorSchema)
+		}
+		return b.ref(msg), nil
+	}
+	d.Components.Schemas.AdditionalProperties = append(d.Components.Schemas.AdditionalProperties, &v3.NamedSchemaOrReference{
+		Name: "Error",
+		Value: schemaValue(&v3.Schema{
+			Type: "object",
+			Properties: &v3.Properties{
+				AdditionalProperties: []*v3.NamedSchemaOrReference{
+					{Name: "code", Value: schemaValue(&v3.Schema{Type: "integer", Format: "int32"})},
+					{Name: "message", Value: schemaValue(&v3.Schema{Type: "string"})},
+				},
+			},
+		}),
+	})
+	return refValue("Error"), nil
+}
+
+func (b *openAPIBuilder) addOperation(d *v3.Document, rt *route, errRef *v3.SchemaOrReference) {
+	m := rt.Method
+	op := &v3.Operation{
+		Tags:        []string{m.Parent.GoName},
+		OperationId: m.Parent.GoName + "_" + m.GoName,
+		Description: comment(m.Comments.Leading),
+		Deprecated:  isDeprecatedMethod(m),
+	}
+
+	covered := map[string]bool{}
+	for _, p := range rt.PathParams {
+		covered[p.Name] = true
+		param := &v3.Parameter{
+			Name:     p.Name,
+			In:       "path",
+			Required: true,
+			Schema:   schemaValue(&v3.Schema{Type: "string"}),
+		}
+		if field := findFieldPath(m.Input, p.Name); field != nil {
+			param.Description = comment(field.Comments.Leading)
+		}
+		op.Parameters = append(op.Parameters, &v3.ParameterOrReference{
+			Oneof: &v3.ParameterOrReference_Parameter{Parameter: param},
+		})
+	}
+
+	switch {
+	case rt.HTTPMethod == "GET":
+		op.Parameters = append(op.Parameters, b.queryParameters(m.Input, "", covered, 0)...)
+	case isHttpBody(m.Input):
+		op.RequestBody = rawRequestBody()
+	case rt.BodyField != nil && isHttpBody(rt.BodyField.Message):
+		covered[string(rt.BodyField.Desc.Name())] = true
+		op.Parameters = append(op.Parameters, b.queryParameters(m.Input, "", covered, 0)...)
+		op.RequestBody = rawRequestBody()
+	case isUpdateMaskInferred(rt):
+		// The body is the resource alone, the other fields, such as update_mask, are query parameters.
+		covered[string(rt.BodyField.Desc.Name())] = true
+		op.Parameters = append(op.Parameters, b.queryParameters(m.Input, "", covered, 0)...)
+		op.RequestBody = messageRequestBody(b.ref(rt.BodyField.Message))
+	default:
+		op.RequestBody = messageRequestBody(b.ref(m.Input))
+	}
+
+	success := &v3.Response{Description: http.StatusText(rt.SuccessCode)}
+	switch {
+	case rt.SuccessCode == http.StatusNoContent, rt.SuccessCode == http.StatusResetContent:
+	case isHttpBody(m.Output):
+		success.Content = mediaTypes("*/*", schemaValue(&v3.Schema{Type: "string", Format: "binary"}))
+	default:
+		success.Content = mediaTypes("application/json", b.ref(m.Output))
+	}
+	op.Responses = &v3.Responses{
+		ResponseOrReference: []*v3.NamedResponseOrReference{
+			{
+				Name:  strconv.Itoa(rt.SuccessCode),
+				Value: &v3.ResponseOrReference{Oneof: &v3.ResponseOrReference_Response{Response: success}},
+			},
+		},
+		Default: &v3.ResponseOrReference{
+			Oneof: &v3.ResponseOrReference_Response{
+				Response: &v3.Response{
+					Description: "Error",
+					Content:     mediaTypes("application/json", errRef),
+				},
+			},
+		},
+	}
+
+	if ext, ok := proto.GetExtension(m.Desc.Options(), v3.E_Operation).(*v3.Operation); ok && ext != nil {
+		proto.Merge(op, ext)
+	}
+
+	p := pathVariableRe.ReplaceAllString(rt.Path, "{$1}")
+	var item *v3.PathItem
+	for _, named := range d.Paths.Path {
+		if named.Name == p {
+			item = named.Value
+		}
+	}
+	if item == nil {
+		item = &v3.PathItem{}
+		d.Paths.Path = append(d.Paths.Path, &v3.NamedPathItem{Name: p, Value: item})
+	}
+	switch rt.HTTPMethod {
+	case "GET":
+		item.Get = op
+	case "PUT":
+		item.Put = op
+	case "POST":
+		item.Post = op
+	case "DELETE":
+		item.Delete = op
+	case "OPTIONS":
+		item.Options = op
+	case "HEAD":
+		item.Head = op
+	case "PATCH":
+		item.Patch = op
+	case "TRACE":
+		item.Trace = op
+	}
+}
+
+// queryParameters lists the fields of msg bound from the query string, skipping the covered ones.
+// Like the query decoder it binds scalar and repeated scalar fields, addressing nested messages as "parent.child".
+func (b *openAPIBuilder) queryParameters(msg *protogen.Message, prefix string, covered map[string]bool, depth int) []*v3.ParameterOrReference {
+	var params []*v3.ParameterOrReference
+	for _, field := range msg.Fields {
+		name := prefix + string(field.Desc.Name())
+		if covered[name] || field.Desc.IsMap() {
+			continue
+		}
+		if field.Oneof != nil && !field.Oneof.Desc.IsSynthetic() {
+			continue
+		}
+		if field.Message != nil {
+			if field.Desc.IsList() || depth >= maxQueryDepth {
+				continue
+			}
+			params = append(params, b.queryParameters(field.Message, name+".", covered, depth+1)...)
+			continue
+		}
+		params = append(params, &v3.ParameterOrReference{
+			Oneof: &v3.ParameterOrReference_Parameter{
+				Parameter: &v3.Parameter{
+					Name:        name,
+					In:          "query",
+					Description: comment(field.Comments.Leading),
+					Schema:      b.fieldSchema(field),
+				},
+			},
+		})
+	}
+	return params
+}
+
+// messageSchema returns the schema of msg as encoding/json marshals it:
+// fields are named after their json tags and the members of a oneof are wrapped in an object named after the oneof.
+func (b *openAPIBuilder) messageSchema(msg *protogen.Message) *v3.SchemaOrReference {
+	s := &v3.Schema{
+		Type:        "object",
+		Description: comment(msg.Comments.Leading),
+		Properties:  &v3.Properties{},
+	}
+	for _, field := range msg.Fields {
+		if oneof := field.Oneof; oneof != nil && !oneof.Desc.IsSynthetic() {
+			if oneof.Fields[0] != field {
+				continue
+			}
+			members := &v3.Properties{}
+			for _, member := range oneof.Fields {
+				members.AdditionalProperties = append(members.AdditionalProperties, &v3.NamedSchemaOrReference{
+					Name:  string(member.Desc.Name()),
+					Value: b.propertySchema(member),
+				})
+			}
+			s.Properties.AdditionalProperties = append(s.Properties.AdditionalProperties, &v3.NamedSchemaOrReference{
+				Name: oneof.GoName,
+				Value: schemaValue(&v3.Schema{
+					Type:        "object",
+					Description: comment(oneof.Comments.Leading),
+					Properties:  members,
+				}),
+			})
+			continue
+		}
+		s.Properties.AdditionalProperties = append(s.Properties.AdditionalProperties, &v3.NamedSchemaOrReference{
+			Name:  string(field.Desc.Name()),
+			Value: b.propertySchema(field),
+		})
+	}
+	if ext, ok := proto.GetExtension(msg.Desc.Options(), v3.E_Schema).(*v3.Schema); ok && ext != nil {
+		proto.Merge(s, ext)
+	}
+	return schemaValue(s)
+}
+
+// propertySchema returns the schema of field as a message property, with its comments and openapi.v3.property annotation.
+func (b *openAPIBuilder) propertySchema(field *protogen.Field) *v3.SchemaOrReference {
+	prop := b.fieldSchema(field)
+	s := prop.GetSchema()
+	if s == nil {
+		return prop
+	}
+	s.Description = comment(field.Comments.Leading)
+	if ext, ok := proto.GetExtension(field.Desc.Options(), v3.E_Property).(*v3.Schema); ok && ext != nil {
+		proto.Merge(s, ext)
+	}
+	return prop
+}
+
+func (b *openAPIBuilder) fieldSchema(field *protogen.Field) *v3.SchemaOrReference {
+	if field.Desc.IsMap() {
+		return schemaValue(&v3.Schema{
+			Type: "object",
+			AdditionalProperties: &v3.AdditionalPropertiesItem{
+				Oneof: &v3.AdditionalPropertiesItem_SchemaOrReference{
+					SchemaOrReference: b.kindSchema(field.Message.Fields[1]),
+				},
+			},
+		})
+	}
+	item := b.kindSchema(field)
+	if field.Desc.IsList() {
+		return schemaValue(&v3.Schema{
+			Type:  "array",
+			Items: &v3.ItemsItem{SchemaOrReference: []*v3.SchemaOrReference{item}},
+		})
+	}
+	return item
+}
+
+func (b *openAPIBuilder) kindSchema(field *protogen.Field) *v3.SchemaOrReference {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return schemaValue(&v3.Schema{Type: "boolean"})
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return schemaValue(&v3.Schema{Type: "integer", Format: "int32"})
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return schemaValue(&v3.Schema{Type: "integer", Format: "uint32"})
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return schemaValue(&v3.Schema{Type: "integer", Format: "int64"})
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return schemaValue(&v3.Schema{Type: "integer", Format: "uint64"})
+	case protoreflect.FloatKind:
+		return schemaValue(&v3.Schema{Type: "number", Format: "float"})
+	case protoreflect.DoubleKind:
+		return schemaValue(&v3.Schema{Type: "number", Format: "double"})
+	case protoreflect.StringKind:
+		return schemaValue(&v3.Schema{Type: "string"})
+	case protoreflect.BytesKind:
+		return schemaValue(&v3.Schema{Type: "string", Format: "byte"})
+	case protoreflect.EnumKind:
+		s := &v3.Schema{Type: "integer", Format: "int32"}
+		for _, value := range field.Enum.Values {
+			s.Enum = append(s.Enum, &v3.Any{Yaml: strconv.Itoa(int(value.Desc.Number()))})
+		}
+		return schemaValue(s)
+	default:
+		return b.ref(field.Message)
+	}
+}
+
+// ref returns a reference to the schema of msg, queueing the schema to be written to the components.
+func (b *openAPIBuilder) ref(msg *protogen.Message) *v3.SchemaOrReference {
+	if !b.seen[msg.Desc.FullName()] {
+		b.seen[msg.Desc.FullName()] = true
+		b.pending = append(b.pending, msg)
+	}
+	return refValue(string(msg.Desc.FullName()))
+}
+
+func (b *openAPIBuilder) findMessage(name protoreflect.FullName) *protogen.Message {
+	var find func(msgs []*protogen.Message) *protogen.Message
+	find = func(msgs []*protogen.Message) *protogen.Message {
+		for _, msg := range msgs {
+			if msg.Desc.FullName() == name {
+				return msg
+			}
+			if found := find(msg.Messages); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	for _, f := range b.gen.Files {
+		if msg := find(f.Messages); msg != nil {
+			return msg
+		}
+	}
+	return nil
+}
+
+// messageRequestBody returns the request body of the message of ref, in JSON or as a form.
+func messageRequestBody(ref *v3.SchemaOrReference) *v3.RequestBodyOrReference {
+	return &v3.RequestBodyOrReference{
+		Oneof: &v3.RequestBodyOrReference_RequestBody{
+			RequestBody: &v3.RequestBody{
+				Content: &v3.MediaTypes{
+					AdditionalProperties: []*v3.NamedMediaType{
+						{Name: "application/json", Value: &v3.MediaType{Schema: ref}},
+						{Name: "application/x-www-form-urlencoded", Value: &v3.MediaType{Schema: ref}},
+						{Name: "multipart/form-data", Value: &v3.MediaType{Schema: ref}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func rawRequestBody() *v3.RequestBodyOrReference {
+	return &v3.RequestBodyOrReference{
+		Oneof: &v3.RequestBodyOrReference_RequestBody{
+			RequestBody: &v3.RequestBody{
+				Content:  mediaTypes("*/*", schemaValue(&v3.Schema{Type: "string", Format: "binary"})),
+				Required: true,
+			},
+		},
+	}
+}
+
+func mediaTypes(name string, schema *v3.SchemaOrReference) *v3.MediaTypes {
+	return &v3.MediaTypes{
+		AdditionalProperties: []*v3.NamedMediaType{
+			{Name: name, Value: &v3.MediaType{Schema: schema}},
+		},
+	}
+}
+
+func schemaValue(s *v3.Schema) *v3.SchemaOrReference {
+	return &v3.SchemaOrReference{Oneof: &v3.SchemaOrReference_Schema{Schema: s}}
+}
+
+func refValue(name string) *v3.SchemaOrReference {
+	return &v3.SchemaOrReference{
+		Oneof: &v3.SchemaOrReference_Reference{
+			Reference: &v3.Reference{XRef: "#/components/schemas/" + name},
+		},
+	}
+}
+
+func comment(c protogen.Comments) string {
+	return strings.TrimSpace(string(c))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const libraryProto = `
+name: "library/v1/library.proto"
+syntax: "proto3"
+package: "library.v1"
+dependency: "google/api/annotations.proto"
+dependency: "http_go/options.proto"
+options: { go_package: "example.com/library/v1;libraryv1" }
+message_type: {
+	name: "Book"
+	field: { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+	field: { name: "pages" number: 2 label: LABEL_OPTIONAL type: TYPE_INT64 }
+	field: { name: "tags" number: 3 label: LABEL_REPEATED type: TYPE_STRING }
+	field: { name: "isbn" number: 4 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 }
+	field: { name: "issn" number: 5 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 }
+	oneof_decl: { name: "id" }
+}
+message_type: {
+	name: "Filter"
+	field: { name: "author" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: {
+	name: "GetBookRequest"
+	field: { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+	field: { name: "view" number: 2 label: LABEL_OPTIONAL type: TYPE_INT32 }
+	field: { name: "filter" number: 3 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".library.v1.Filter" }
+}
+message_type: {
+	name: "DeleteBookRequest"
+	field: { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+service: {
+	name: "Library"
+	method: {
+		name: "GetBook"
+		input_type: ".library.v1.GetBookRequest"
+		output_type: ".library.v1.Book"
+		options: { [google.api.http]: { get: "/v1/books/{name}" } }
+	}
+	method: {
+		name: "DeleteBook"
+		input_type: ".library.v1.DeleteBookRequest"
+		output_type: ".library.v1.Book"
+		options: {
+			[google.api.http]: { delete: "/v1/books/{name}" body: "*" }
+			[http_go.method]: { success_code: 204 }
+		}
+	}
+}
+`
+
+func TestGenerateOpenAPI(t *testing.T) {
+	gen := newTestPlugin(t, "openapi=file,openapi_version=1.2.3", libraryProto)
+	for _, f := range gen.Files {
+		if f.Generate {
+			if err := generateOpenAPI(gen, f); err != nil {
+				t.Fatalf("generateOpenAPI() failed with %v", err)
+			}
+		}
+	}
+	doc := generatedContent(t, gen, "example.com/library/v1/library.openapi.yaml")
+
+	for _, want := range []string{
+		"title: Library API",
+		"version: 1.2.3",
+		"/v1/books/{name}:",
+		// query parameters are flattened like the query decoder expects them
+		"name: view\n                  in: query",
+		"name: filter.author\n                  in: query",
+		// success codes follow http_go.method
+		`"204":` + "\n                    description: No Content\n",
+		// encoding/json writes int64 as numbers and wraps oneof members
+		"pages:\n                    type: integer\n                    format: int64",
+		"Id:\n                    type: object\n                    properties:\n                        isbn:",
+		"$ref: '#/components/schemas/Error'",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("generated document does not contain %q:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, "name: name\n                  in: query") {
+		t.Errorf("path parameter name is documented as a query parameter:\n%s", doc)
+	}
+}
+
+func TestGenerateOpenAPIJSON(t *testing.T) {
+	gen := newTestPlugin(t, "serve_openapi=true", libraryProto)
+	if err := generateTestFiles(t, gen); err != nil {
+		t.Fatalf("generateFile() failed with %v", err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+		} `json:"paths"`
+	}
+	content := generatedContent(t, gen, "example.com/library/v1/library.openapi.json")
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		t.Fatalf("json.Unmarshal(%s) failed with %v", content, err)
+	}
+	if got, want := doc.Paths["/v1/books/{name}"]["get"].OperationID, "Library_GetBook"; got != want {
+		t.Errorf("operationId of GET /v1/books/{name} = %q; want %q", got, want)
+	}
+
+	code := generatedContent(t, gen, "example.com/library/v1/library_http.pb.go")
+	for _, want := range []string{
+		"//go:embed library.openapi.json\nvar file_library_v1_library_proto_openapi []byte",
+		"HandleOpenAPI(mux, file_library_v1_library_proto_openapi)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+}
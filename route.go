@@ -0,0 +1,450 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peterchanxyz/protoc-gen-http-go/options"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// The protocols routes serve their method with, the values of runtime.Route.Protocol.
+const (
+	protocolREST    = "rest"
+	protocolConnect = "connect"
+	protocolTwirp   = "twirp"
+	protocolGRPCWeb = "grpc-web"
+)
+
+// route is the HTTP binding of a method.
+// It is the single model both the handlers and the OpenAPI documents are generated from.
+type route struct {
+	Method       *protogen.Method
+	Protocol     string
+	HTTPMethod   string
+	Path         string
+	PathParams   []*pathParam
+	Body         string
+	BodyField    *protogen.Field
+	ResponseBody string
+	SuccessCode  int
+}
+
+// FullMethod returns the gRPC full method name of the route, e.g. "/library.v1.Library/GetBook".
+func (r *route) FullMethod() string {
+	return "/" + string(r.Method.Parent.Desc.FullName()) + "/" + string(r.Method.Desc.Name())
+}
+
+// Pattern returns the http.ServeMux pattern of the route.
+func (r *route) Pattern() string {
+	if r.HTTPMethod == "" {
+		return r.Path
+	}
+	return r.HTTPMethod + " " + r.Path
+}
+
+func buildRoute(m *protogen.Method) (*route, error) {
+	r := &route{
+		Method:      m,
+		Protocol:    protocolREST,
+		SuccessCode: http.StatusOK,
+	}
+	if rule := httpRule(m); rule != nil {
+		r.HTTPMethod, r.Path = buildHTTPRule(m, rule)
+		r.Body = rule.Body
+		r.ResponseBody = rule.ResponseBody
+	} else {
+		r.HTTPMethod, r.Path = defaultRoute(m)
+		r.Body = "*"
+	}
+
+	var err error
+	r.PathParams, err = parsePathParam(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %v", sourcePos(m.Desc), m.Desc.FullName(), err)
+	}
+
+	if r.Body != "" && r.Body != "*" {
+		r.BodyField = findField(m.Input, r.Body)
+	}
+
+	if isLongRunning(m) {
+		r.SuccessCode = http.StatusAccepted
+	}
+	if mopts := methodOptions(m); mopts.GetSuccessCode() != 0 {
+		r.SuccessCode = int(mopts.GetSuccessCode())
+		if r.SuccessCode < 200 || r.SuccessCode > 299 {
+			return nil, fmt.Errorf("%s: success_code %d is not a 2xx status", m.Desc.FullName(), r.SuccessCode)
+		}
+	}
+	return r, nil
+}
+
+// buildRoutes returns the REST routes of the methods of s.
+func buildRoutes(s *protogen.Service) ([]*route, error) {
+	routes := make([]*route, 0, len(s.Methods))
+	for _, method := range s.Methods {
+		if !isServed(method) || !hasRESTRoute(method) {
+			continue
+		}
+		r, err := buildRoute(method)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// connectRoute returns the route of m in the Connect protocol, POST /package.Service/Method.
+// Its pattern has no method so that the handler answers GET too for methods without side effects.
+func connectRoute(m *protogen.Method) *route {
+	return &route{
+		Method:      m,
+		Protocol:    protocolConnect,
+		Path:        "/" + string(m.Parent.Desc.FullName()) + "/" + string(m.Desc.Name()),
+		Body:        "*",
+		SuccessCode: http.StatusOK,
+	}
+}
+
+// twirpRoute returns the route of m in the Twirp protocol, POST /twirp/package.Service/Method.
+func twirpRoute(m *protogen.Method) *route {
+	return &route{
+		Method:      m,
+		Protocol:    protocolTwirp,
+		HTTPMethod:  http.MethodPost,
+		Path:        "/twirp/" + string(m.Parent.Desc.FullName()) + "/" + string(m.Desc.Name()),
+		Body:        "*",
+		SuccessCode: http.StatusOK,
+	}
+}
+
+// grpcWebRoute returns the route of m in the gRPC-Web protocol, POST /package.Service/Method.
+func grpcWebRoute(m *protogen.Method) *route {
+	return &route{
+		Method:      m,
+		Protocol:    protocolGRPCWeb,
+		HTTPMethod:  http.MethodPost,
+		Path:        "/" + string(m.Parent.Desc.FullName()) + "/" + string(m.Desc.Name()),
+		Body:        "*",
+		SuccessCode: http.StatusOK,
+	}
+}
+
+// rpcRoutes returns the routes of m in the RPC protocols enabled by the plugin options, as registered on the ServeMux.
+// A unary method served with both Connect and gRPC-Web has the Connect route only, whose handler
+// passes the gRPC-Web requests on according to their Content-Type.
+func rpcRoutes(m *protogen.Method) []*route {
+	var routes []*route
+	if isUnary(m) && *connect {
+		routes = append(routes, connectRoute(m))
+	}
+	if isUnary(m) && *twirp {
+		routes = append(routes, twirpRoute(m))
+	}
+	if *grpcWeb && grpcWebHost(m) == nil {
+		routes = append(routes, grpcWebRoute(m))
+	}
+	return routes
+}
+
+// grpcWebHost returns the route whose handler also serves the gRPC-Web requests of m, passing them on according to
+// their Content-Type, or nil when the gRPC-Web route of m has a handler of its own.
+// Both the Connect route and the rpc default route of a unary method have the path of its gRPC-Web route.
+func grpcWebHost(m *protogen.Method) *route {
+	switch {
+	case !isUnary(m):
+		return nil
+	case *connect:
+		return connectRoute(m)
+	case httpRule(m) == nil && *defaultRoutes == "rpc":
+		method, path := defaultRoute(m)
+		return &route{Method: m, Protocol: protocolREST, HTTPMethod: method, Path: path}
+	}
+	return nil
+}
+
+// methodTimeout returns the timeout option of m, or 0 if it has none.
+func methodTimeout(m *protogen.Method) (time.Duration, error) {
+	timeout := methodOptions(m).GetTimeout()
+	if timeout == nil {
+		return 0, nil
+	}
+	if err := timeout.CheckValid(); err != nil || timeout.AsDuration() <= 0 {
+		return 0, fmt.Errorf("%s: %s: timeout %v is not a positive duration", sourcePos(m.Desc), m.Desc.FullName(), timeout.AsDuration())
+	}
+	return timeout.AsDuration(), nil
+}
+
+// preflight is the OPTIONS route answering the CORS preflight requests of a path,
+// for the methods of the routes registered with that path.
+type preflight struct {
+	Path    string
+	Methods []string
+	// Route is the first route of the path, the one conflicts are reported against.
+	Route *route
+}
+
+// preflights returns the preflight routes of the REST and RPC routes of s, one per distinct path in registration order.
+// Paths whose OPTIONS patterns would conflict on the ServeMux, such as /v1/{book} and /v1/{shelf}
+// of a GET and a DELETE route, share the preflight route of the first one, which allows the methods of both.
+func preflights(s *protogen.Service) ([]*preflight, error) {
+	routes, err := buildRoutes(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range servedMethods(s) {
+		routes = append(routes, rpcRoutes(m)...)
+	}
+	var pfs []*preflight
+	byPattern := map[string]*preflight{}
+	mux := http.NewServeMux()
+	for _, r := range routes {
+		pf := &preflight{Path: r.Path, Route: r}
+		if other, ok := registerPreflight(mux, byPattern, pf); ok {
+			pfs = append(pfs, pf)
+		} else {
+			pf = other
+		}
+		methods := []string{r.HTTPMethod}
+		if r.HTTPMethod == "" {
+			// A Connect route: POST, and GET for the methods without side effects.
+			methods = []string{http.MethodPost}
+			if isIdempotent(r.Method) {
+				methods = append(methods, http.MethodGet)
+			}
+		}
+		for _, method := range methods {
+			if !slices.Contains(pf.Methods, method) {
+				pf.Methods = append(pf.Methods, method)
+			}
+		}
+	}
+	return pfs, nil
+}
+
+// registerPreflight registers the pattern of pf on mux and reports whether it is new.
+// Otherwise it returns the preflight already registered with the same or a conflicting pattern.
+func registerPreflight(mux *http.ServeMux, byPattern map[string]*preflight, pf *preflight) (other *preflight, ok bool) {
+	pattern := pf.route().Pattern()
+	if other, found := byPattern[pattern]; found {
+		return other, false
+	}
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+		if m := conflictRe.FindStringSubmatch(fmt.Sprint(p)); m != nil {
+			pattern, _ := strconv.Unquote(m[1])
+			if found, exists := byPattern[pattern]; exists {
+				other, ok = found, false
+				return
+			}
+		}
+		// The route itself is invalid, which checkRoutes reports; it gets no preflight of its own.
+		other, ok = pf, false
+	}()
+	mux.Handle(pattern, http.NotFoundHandler())
+	byPattern[pattern] = pf
+	return nil, true
+}
+
+// route returns the OPTIONS route of p, as registered on the ServeMux.
+func (p *preflight) route() *route {
+	return &route{Method: p.Route.Method, HTTPMethod: http.MethodOptions, Path: p.Path}
+}
+
+// servedMethods returns the methods of s that have a handler: a REST route or the route of an RPC protocol.
+func servedMethods(s *protogen.Service) []*protogen.Method {
+	var methods []*protogen.Method
+	for _, m := range s.Methods {
+		if isServed(m) && (hasRESTRoute(m) || len(rpcRoutes(m)) > 0) {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// isServed reports whether m may be exposed over HTTP: neither it nor its service is excluded, and it is unary,
+// or server-streaming when gRPC-Web is enabled.
+func isServed(m *protogen.Method) bool {
+	if m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer() && !*grpcWeb {
+		return false
+	}
+	return !isExcludedService(m.Parent) && !methodOptions(m).GetExclude()
+}
+
+// isUnary reports whether m takes and returns a single message.
+func isUnary(m *protogen.Method) bool {
+	return !m.Desc.IsStreamingClient() && !m.Desc.IsStreamingServer()
+}
+
+// hasRESTRoute reports whether m has a REST route: a google.api.http rule, or a default route.
+// The rpc default route is left to the Connect handler when there is one, which serves the same path.
+// A gRPC-Web handler shares it instead, see grpcWebHost.
+func hasRESTRoute(m *protogen.Method) bool {
+	switch {
+	case !isUnary(m):
+		return false
+	case httpRule(m) != nil:
+		return true
+	case *defaultRoutes == "rpc":
+		return !*connect
+	}
+	return *defaultRoutes == "rest"
+}
+
+// isIdempotent reports whether m is marked with idempotency_level = NO_SIDE_EFFECTS, which Connect serves over GET.
+func isIdempotent(m *protogen.Method) bool {
+	mopts, ok := m.Desc.Options().(*descriptorpb.MethodOptions)
+	return ok && mopts.GetIdempotencyLevel() == descriptorpb.MethodOptions_NO_SIDE_EFFECTS
+}
+
+// isExcludedService reports whether s is excluded from HTTP exposure with the http_go.service option.
+func isExcludedService(s *protogen.Service) bool {
+	sopts, _ := proto.GetExtension(s.Desc.Options(), options.E_Service).(*options.ServiceOptions)
+	return sopts.GetExclude()
+}
+
+// servedServices returns the services of file that are not excluded from HTTP exposure.
+func servedServices(file *protogen.File) []*protogen.Service {
+	var services []*protogen.Service
+	for _, s := range file.Services {
+		if !isExcludedService(s) {
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// httpRule returns the google.api.http rule of m, or nil.
+func httpRule(m *protogen.Method) *annotations.HttpRule {
+	rule, _ := proto.GetExtension(m.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+	return rule
+}
+
+// methodOptions returns the http_go.method options of m, or nil.
+func methodOptions(m *protogen.Method) *options.MethodOptions {
+	mopts, _ := proto.GetExtension(m.Desc.Options(), options.E_Method).(*options.MethodOptions)
+	return mopts
+}
+
+// defaultRoute returns the route of a method without google.api.http rule in the default_routes scheme.
+// Both schemes POST the whole request as the body:
+//
+//	rpc:  POST /library.v1.Library/GetBook, like Twirp and Connect
+//	rest: POST /library/v1/library/get-book
+func defaultRoute(m *protogen.Method) (method, path string) {
+	if *defaultRoutes == "rest" {
+		pkg := strings.ReplaceAll(string(m.Parent.Desc.ParentFile().Package()), ".", "/")
+		if pkg != "" {
+			pkg = "/" + pkg
+		}
+		return "POST", pkg + "/" + toKebabCase(string(m.Parent.Desc.Name())) + "/" + toKebabCase(string(m.Desc.Name()))
+	}
+	return "POST", "/" + string(m.Parent.Desc.FullName()) + "/" + string(m.Desc.Name())
+}
+
+// routeSet collects the routes of a plugin run and reports the ones http.ServeMux would refuse to register,
+// so that a conflict fails generation instead of panicking in RegisterHttpServer at startup.
+type routeSet struct {
+	mux    *http.ServeMux
+	routes map[string]*route
+}
+
+func newRouteSet() *routeSet {
+	return &routeSet{
+		mux:    http.NewServeMux(),
+		routes: map[string]*route{},
+	}
+}
+
+// conflictRe matches the pattern a ServeMux registration conflicts with in the message of its panic.
+var conflictRe = regexp.MustCompile(`conflicts with pattern ("(?:[^"\\]|\\.)*")`)
+
+// add registers r, returning an error if its pattern is invalid or conflicts with a route added before.
+func (s *routeSet) add(r *route) (err error) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+		msg := fmt.Sprint(p)
+		m := conflictRe.FindStringSubmatch(msg)
+		if m == nil {
+			err = fmt.Errorf("%s: %s: invalid route %q: %s", sourcePos(r.Method.Desc), r.Method.Desc.FullName(), r.Pattern(), msg)
+			return
+		}
+		pattern, _ := strconv.Unquote(m[1])
+		other, ok := s.routes[pattern]
+		if !ok {
+			err = fmt.Errorf("%s: %s: route %q conflicts with %q", sourcePos(r.Method.Desc), r.Method.Desc.FullName(), r.Pattern(), pattern)
+			return
+		}
+		// The explanation of the ServeMux follows the first line, e.g. "GET /a matches the same requests as GET /a".
+		reason := ""
+		if i := strings.Index(msg, ":\n"); i >= 0 {
+			reason = ": " + strings.ReplaceAll(msg[i+2:], "\n", " ")
+		}
+		err = fmt.Errorf("%s: %s: route %q conflicts with route %q of %s at %s%s",
+			sourcePos(r.Method.Desc), r.Method.Desc.FullName(), r.Pattern(),
+			other.Pattern(), other.Method.Desc.FullName(), sourcePos(other.Method.Desc), reason)
+	}()
+	s.mux.Handle(r.Pattern(), http.NotFoundHandler())
+	s.routes[r.Pattern()] = r
+	return nil
+}
+
+// checkRoutes builds the routes of all the services to generate and reports the first two that collide
+// or would be ambiguous on one http.ServeMux. The routes include the OPTIONS routes RegisterHttpServer registers
+// under WithCORS, which are merged per service only: two services sharing a path would register it twice,
+// and the operations route of the long-running methods, which HandleOperations registers once for all the services.
+func checkRoutes(gen *protogen.Plugin) error {
+	set := newRouteSet()
+	operations := false
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		for _, s := range f.Services {
+			routes, err := buildRoutes(s)
+			if err != nil {
+				return err
+			}
+			for _, m := range servedMethods(s) {
+				routes = append(routes, rpcRoutes(m)...)
+			}
+			for _, r := range routes {
+				if err := set.add(r); err != nil {
+					return err
+				}
+			}
+			pfs, err := preflights(s)
+			if err != nil {
+				return err
+			}
+			for _, pf := range pfs {
+				if err := set.add(pf.route()); err != nil {
+					return fmt.Errorf("%w (the CORS preflight route of %s)", err, pf.Path)
+				}
+			}
+			if rt := operationsRoute(s); rt != nil && !operations {
+				operations = true
+				if err := set.add(rt); err != nil {
+					return fmt.Errorf("%w (the operations route of the long-running methods)", err)
+				}
+			}
+		}
+	}
+	return nil
+}
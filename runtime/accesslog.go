@@ -0,0 +1,164 @@
+package runtime
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// redacted replaces the values of the fields with the debug_redact option in access logs.
+const redacted = "REDACTED"
+
+// WithAccessLog makes the generated handlers log one record per call to logger, with the full method name,
+// the route, the HTTP status, the error code and message, the duration and the request fields at the given paths,
+// e.g. "name" or "book.title". "*" logs the whole request. Paths missing from the request of a method are skipped,
+// and the values of fields with the debug_redact option are logged as "REDACTED".
+// Calls are logged at the info level, or at the error level when they fail with a server error.
+func WithAccessLog(logger *slog.Logger, fields ...string) ServerOption {
+	return WithStatsHandler(&accessLog{logger: logger, fields: fields})
+}
+
+// accessLog is the StatsHandler of WithAccessLog.
+type accessLog struct {
+	logger *slog.Logger
+	fields []string
+}
+
+// accessLogCall holds the request fields of a call until it ends.
+type accessLogCall struct {
+	mu     sync.Mutex
+	fields []slog.Attr
+}
+
+type accessLogKey struct{}
+
+func (l *accessLog) Begin(ctx context.Context, info *CallInfo) context.Context {
+	return context.WithValue(ctx, accessLogKey{}, &accessLogCall{})
+}
+
+func (l *accessLog) Decoded(ctx context.Context, info *CallInfo, req proto.Message) {
+	c, ok := ctx.Value(accessLogKey{}).(*accessLogCall)
+	if !ok || len(l.fields) == 0 {
+		return
+	}
+	var attrs []slog.Attr
+	m := req.ProtoReflect()
+	for _, path := range l.fields {
+		if path == "*" {
+			attrs = append(attrs, messageAttrs(m)...)
+			continue
+		}
+		if v, ok := fieldValue(m, path); ok {
+			attrs = append(attrs, slog.Attr{Key: path, Value: v})
+		}
+	}
+	c.mu.Lock()
+	c.fields = attrs
+	c.mu.Unlock()
+}
+
+func (l *accessLog) End(ctx context.Context, info *CallInfo, stats *CallStats) {
+	attrs := []slog.Attr{
+		slog.String("method", info.FullMethod),
+		slog.String("route", info.Route),
+		slog.String("http_method", info.HTTPMethod),
+		slog.Int("status", stats.Status),
+		slog.String("code", stats.Code.String()),
+		slog.Duration("duration", stats.Duration),
+	}
+	level := slog.LevelInfo
+	if stats.Err != nil {
+		attrs = append(attrs, slog.String("error", messageOf(stats.Err)))
+	}
+	if stats.Code.HTTPStatus() >= 500 || stats.Status >= 500 {
+		level = slog.LevelError
+	}
+	if c, ok := ctx.Value(accessLogKey{}).(*accessLogCall); ok {
+		c.mu.Lock()
+		if len(c.fields) > 0 {
+			attrs = append(attrs, slog.Attr{Key: "request", Value: slog.GroupValue(c.fields...)})
+		}
+		c.mu.Unlock()
+	}
+	l.logger.LogAttrs(ctx, level, "call", attrs...)
+}
+
+// fieldValue returns the log value of the field of m at the dot-separated path of proto field names.
+func fieldValue(m protoreflect.Message, path string) (slog.Value, bool) {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return slog.Value{}, false
+		}
+		if isRedacted(fd) {
+			return slog.StringValue(redacted), true
+		}
+		if i == len(names)-1 {
+			return logValue(fd, m.Get(fd)), true
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return slog.Value{}, false
+		}
+		m = m.Get(fd).Message()
+	}
+	return slog.Value{}, false
+}
+
+// messageAttrs returns the log attributes of the populated fields of m.
+func messageAttrs(m protoreflect.Message) []slog.Attr {
+	var attrs []slog.Attr
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		attrs = append(attrs, slog.Attr{Key: string(fd.Name()), Value: logValue(fd, v)})
+		return true
+	})
+	return attrs
+}
+
+// logValue returns the log value of v, the value of the field fd, with its redacted fields replaced.
+func logValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) slog.Value {
+	if isRedacted(fd) {
+		return slog.StringValue(redacted)
+	}
+	switch {
+	case fd.IsList():
+		list := v.List()
+		values := make([]any, list.Len())
+		for i := range values {
+			values[i] = singularLogValue(fd, list.Get(i))
+		}
+		return slog.AnyValue(values)
+	case fd.IsMap():
+		var attrs []slog.Attr
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			attrs = append(attrs, slog.Attr{Key: k.String(), Value: singularLogValue(fd.MapValue(), v)})
+			return true
+		})
+		return slog.GroupValue(attrs...)
+	}
+	return singularLogValue(fd, v)
+}
+
+func singularLogValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) slog.Value {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return slog.GroupValue(messageAttrs(v.Message())...)
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return slog.StringValue(string(ev.Name()))
+		}
+		return slog.Int64Value(int64(v.Enum()))
+	}
+	return slog.AnyValue(v.Interface())
+}
+
+// isRedacted reports whether fd has the debug_redact option.
+func isRedacted(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	return ok && opts.GetDebugRedact()
+}
@@ -0,0 +1,111 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// loginRequest returns a login.v1.LoginRequest message whose password and token fields have the debug_redact option.
+func loginRequest(t *testing.T) *dynamicpb.Message {
+	t.Helper()
+	md := testMessages(t, `
+syntax: "proto3"
+name: "login/v1/login.proto"
+package: "login.v1"
+message_type: {
+	name: "Credentials"
+	field: { name: "password" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "password" options: { debug_redact: true } }
+	field: { name: "otp" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "otp" }
+}
+message_type: {
+	name: "LoginRequest"
+	field: { name: "user" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "user" }
+	field: { name: "credentials" number: 2 type: TYPE_MESSAGE type_name: ".login.v1.Credentials" label: LABEL_OPTIONAL json_name: "credentials" }
+	field: { name: "token" number: 3 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "token" options: { debug_redact: true } }
+}
+`).ByName("LoginRequest")
+	return newTestMessage(t, md, `user: "ada" token: "t0k3n" credentials: { password: "hunter2" otp: "123456" }`)
+}
+
+func TestAccessLog(t *testing.T) {
+	for _, spec := range []struct {
+		name        string
+		fields      []string
+		err         error
+		wantLevel   string
+		wantRequest map[string]any
+	}{
+		{
+			name:        "selected fields",
+			fields:      []string{"user", "credentials.otp", "credentials.password", "token", "missing"},
+			wantLevel:   "INFO",
+			wantRequest: map[string]any{"user": "ada", "credentials.otp": "123456", "credentials.password": "REDACTED", "token": "REDACTED"},
+		},
+		{
+			name:      "whole request",
+			fields:    []string{"*"},
+			err:       NewError(CodeUnavailable, "try later"),
+			wantLevel: "ERROR",
+			wantRequest: map[string]any{
+				"user":        "ada",
+				"credentials": map[string]any{"password": "REDACTED", "otp": "123456"},
+				"token":       "REDACTED",
+			},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			o := NewServerOptions(WithAccessLog(slog.New(slog.NewJSONHandler(&buf, nil)), spec.fields...))
+			h := o.Instrument("/login.v1.Login/Login", "/v1/login", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := o.NewContext(r)
+				o.Decoded(ctx, loginRequest(t))
+				if spec.err != nil {
+					o.WriteError(ctx, w, spec.err)
+					return
+				}
+				o.WriteResponse(ctx, w, http.StatusOK, struct{}{})
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/login", nil))
+
+			if strings.Count(buf.String(), "\n") != 1 {
+				t.Fatalf("logged %q, want one record", buf.String())
+			}
+			record := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("json.Unmarshal() failed with %v", err)
+			}
+			if record["level"] != spec.wantLevel || record["method"] != "/login.v1.Login/Login" || record["route"] != "/v1/login" {
+				t.Errorf("record %v, want a %s record of /login.v1.Login/Login at /v1/login", record, spec.wantLevel)
+			}
+			if _, ok := record["duration"]; !ok {
+				t.Errorf("record %v has no duration", record)
+			}
+			if spec.err != nil && (record["code"] != "unavailable" || record["status"] != float64(503) || record["error"] != "try later") {
+				t.Errorf("record %v, want the unavailable error and its 503 status", record)
+			}
+			if !reflect.DeepEqual(record["request"], spec.wantRequest) {
+				t.Errorf("request = %v, want %v", record["request"], spec.wantRequest)
+			}
+		})
+	}
+}
+
+func TestAccessLogNotDecoded(t *testing.T) {
+	var buf bytes.Buffer
+	o := NewServerOptions(WithAccessLog(slog.New(slog.NewTextHandler(&buf, nil)), "user"))
+	h := o.Instrument("/login.v1.Login/Login", "/v1/login", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o.WriteError(o.NewContext(r), w, NewError(CodeInvalidArgument, "bad request"))
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/login", nil))
+	if !strings.Contains(buf.String(), "code=invalid_argument") || strings.Contains(buf.String(), " request.") {
+		t.Errorf("logged %q, want the invalid_argument error without request fields", buf.String())
+	}
+}
@@ -0,0 +1,188 @@
+package runtime
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultCompressMinSize is the size from which response bodies are compressed when WithCompressMinSize is not given.
+const DefaultCompressMinSize = 1024
+
+// Compressor compresses and decompresses bodies with an HTTP content coding.
+type Compressor interface {
+	// Name is the content coding in the Content-Encoding and Accept-Encoding headers, e.g. "gzip".
+	Name() string
+	// NewWriter returns a writer compressing into w. Its Close flushes the compressed data without closing w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader decompressing r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]Compressor{"gzip": gzipCompressor{}}
+)
+
+// RegisterCompressor makes c available to the generated handlers, replacing any compressor with the same name.
+// gzip is registered by default, other codings such as zstd can be plugged in from an init function:
+//
+//	func init() { runtime.RegisterCompressor(zstdCompressor{}) }
+func RegisterCompressor(c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[strings.ToLower(c.Name())] = c
+}
+
+func compressor(name string) Compressor {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	return compressors[strings.ToLower(name)]
+}
+
+// WithCompressMinSize sets the size from which response bodies are compressed with the registered coding
+// the client prefers in its Accept-Encoding header. A negative size disables response compression.
+func WithCompressMinSize(n int) ServerOption {
+	return func(o *ServerOptions) {
+		o.compressMinSize = n
+	}
+}
+
+type compressorKey struct{}
+
+// withCompressor returns ctx carrying the compressor negotiated for the response to r, if any.
+func (o *ServerOptions) withCompressor(ctx context.Context, r *http.Request) context.Context {
+	if o.compressMinSize < 0 {
+		return ctx
+	}
+	if c := acceptedCompressor(r.Header.Values("Accept-Encoding")); c != nil {
+		return context.WithValue(ctx, compressorKey{}, c)
+	}
+	return ctx
+}
+
+// acceptedCompressor returns the registered compressor with the highest weight in the Accept-Encoding values,
+// the first listed one among equal weights, or nil if none is acceptable.
+func acceptedCompressor(values []string) Compressor {
+	var best Compressor
+	bestQ := 0.0
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			q := 1.0
+			if qv, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				var err error
+				if q, err = strconv.ParseFloat(qv, 64); err != nil {
+					continue
+				}
+			}
+			c := compressor(strings.TrimSpace(name))
+			if c != nil && q > bestQ {
+				best, bestQ = c, q
+			}
+		}
+	}
+	return best
+}
+
+// writeBody writes data as the response body with the status code, compressed with the coding negotiated
+// for ctx when it is large enough. It must be called after writeHeader.
+func (o *ServerOptions) writeBody(ctx context.Context, w http.ResponseWriter, code int, data []byte) {
+	c := o.bodyCompressor(ctx, data)
+	if c == nil {
+		w.WriteHeader(code)
+		w.Write(data)
+		return
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("Content-Encoding", c.Name())
+	w.Header().Del("Content-Length")
+	w.WriteHeader(code)
+	cw, err := c.NewWriter(w)
+	if err != nil {
+		return
+	}
+	cw.Write(data)
+	cw.Close()
+}
+
+// bodyCompressor returns the compressor of the coding negotiated for ctx when the response body data is large enough
+// to be compressed, or nil.
+func (o *ServerOptions) bodyCompressor(ctx context.Context, data []byte) Compressor {
+	c, _ := ctx.Value(compressorKey{}).(Compressor)
+	if c == nil || len(data) < o.compressMinSize {
+		return nil
+	}
+	return c
+}
+
+// requestBody limits the body of r to the maximum body size and decompresses it according to its Content-Encoding.
+// The limit applies to the decompressed body.
+func (o *ServerOptions) requestBody(r *http.Request) error {
+	if coding := r.Header.Get("Content-Encoding"); coding != "" && !strings.EqualFold(coding, "identity") {
+		c := compressor(strings.TrimSpace(coding))
+		if c == nil {
+			return Errorf(CodeUnimplemented, "unsupported Content-Encoding %q", coding)
+		}
+		zr, err := c.NewReader(r.Body)
+		if err != nil {
+			return Errorf(CodeInvalidArgument, "decompress request: %v", err)
+		}
+		r.Body = &decompressReader{zr: zr, body: r.Body}
+		r.Header.Del("Content-Encoding")
+		r.Header.Del("Content-Length")
+		r.ContentLength = -1
+	}
+	o.limitBody(r)
+	return nil
+}
+
+// decompressReader reads a decompressed request body, reporting corrupt data as an invalid argument.
+type decompressReader struct {
+	zr   io.ReadCloser
+	body io.Closer
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	n, err := d.zr.Read(p)
+	if err != nil && err != io.EOF {
+		err = Errorf(CodeInvalidArgument, "decompress request: %v", err)
+	}
+	return n, err
+}
+
+func (d *decompressReader) Close() error {
+	d.zr.Close()
+	return d.body.Close()
+}
+
+// gzipCompressor is the gzip Compressor, pooling its writers.
+type gzipCompressor struct{}
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+
+func (gzipCompressor) Name() string { return "gzip" }
+
+func (gzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	zw := gzipWriters.Get().(*gzip.Writer)
+	zw.Reset(w)
+	return &pooledGzipWriter{zw}, nil
+}
+
+func (gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+type pooledGzipWriter struct {
+	*gzip.Writer
+}
+
+func (w *pooledGzipWriter) Close() error {
+	err := w.Writer.Close()
+	gzipWriters.Put(w.Writer)
+	return err
+}
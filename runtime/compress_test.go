@@ -0,0 +1,129 @@
+package runtime
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// deflateCompressor is a Compressor registered by the tests to check pluggable codings.
+type deflateCompressor struct{}
+
+func (deflateCompressor) Name() string { return "deflate" }
+
+func (deflateCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.DefaultCompression)
+}
+
+func (deflateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip.Close() failed with %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompressResponse(t *testing.T) {
+	RegisterCompressor(deflateCompressor{})
+	large := strings.Repeat("x", 2000)
+	for _, spec := range []struct {
+		name           string
+		acceptEncoding string
+		value          string
+		opts           []ServerOption
+		wantEncoding   string
+	}{
+		{name: "gzip", acceptEncoding: "gzip", value: large, wantEncoding: "gzip"},
+		{name: "small", acceptEncoding: "gzip", value: "small"},
+		{name: "not accepted", value: large},
+		{name: "unknown coding", acceptEncoding: "br", value: large},
+		{name: "weights", acceptEncoding: "gzip;q=0.5, deflate", value: large, wantEncoding: "deflate"},
+		{name: "refused", acceptEncoding: "gzip;q=0", value: large},
+		{name: "min size", acceptEncoding: "gzip", value: "small", opts: []ServerOption{WithCompressMinSize(0)}, wantEncoding: "gzip"},
+		{name: "disabled", acceptEncoding: "gzip", value: large, opts: []ServerOption{WithCompressMinSize(-1)}},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			o := NewServerOptions(spec.opts...)
+			r := httptest.NewRequest(http.MethodGet, "/v1/books/1", nil)
+			if spec.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", spec.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			o.WriteResponse(o.NewContext(r), w, http.StatusOK, map[string]string{"value": spec.value})
+
+			if got := w.Header().Get("Content-Encoding"); got != spec.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, spec.wantEncoding)
+			}
+			body := io.Reader(w.Body)
+			switch spec.wantEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() failed with %v", err)
+				}
+				body = zr
+			case "deflate":
+				body = flate.NewReader(body)
+			}
+			data, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("reading the body failed with %v", err)
+			}
+			if want := `{"value":"` + spec.value + `"}` + "\n"; string(data) != want {
+				t.Errorf("body = %.40q, want %.40q", data, want)
+			}
+		})
+	}
+}
+
+func TestDecompressRequest(t *testing.T) {
+	for _, spec := range []struct {
+		name     string
+		encoding string
+		body     []byte
+		opts     []ServerOption
+		want     string
+		wantCode Code
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipped(t, `{"value":"Dune"}`), want: "Dune"},
+		{name: "identity", encoding: "identity", body: []byte(`{"value":"Dune"}`), want: "Dune"},
+		{name: "unsupported", encoding: "br", body: []byte(`{}`), wantCode: CodeUnimplemented},
+		{name: "corrupt", encoding: "gzip", body: []byte("not gzip"), wantCode: CodeInvalidArgument},
+		{
+			name:     "decompressed too large",
+			encoding: "gzip",
+			body:     gzipped(t, `{"value":"`+strings.Repeat("x", 1000)+`"}`),
+			opts:     []ServerOption{WithMaxBodySize(100)},
+			wantCode: CodeResourceExhausted,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			o := NewServerOptions(spec.opts...)
+			r := httptest.NewRequest(http.MethodPost, "/v1/books", bytes.NewReader(spec.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Content-Encoding", spec.encoding)
+			in := &wrapperspb.StringValue{}
+			err := o.DecodeBody(r, in)
+			if got := CodeOf(err); got != spec.wantCode {
+				t.Fatalf("DecodeBody() = %v, want code %v", err, spec.wantCode)
+			}
+			if in.GetValue() != spec.want {
+				t.Errorf("DecodeBody() value = %q, want %q", in.GetValue(), spec.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Connect codecs, named like the encoding query parameter of GET requests.
+const (
+	connectJSON  = "json"
+	connectProto = "proto"
+)
+
+// ServeConnect serves r with the unary Connect protocol, see https://connectrpc.com/docs/protocol.
+//
+// The request is decoded into in, from a POST body in application/json or application/proto, or, when get is set
+// for methods without side effects, from the message query parameter of a GET. call then runs the method with the
+// context of the request, which expires after the Connect-Timeout-Ms header if any, capped by the timeout of the method. The response is encoded with
+// the codec of the request and errors are written as Connect error JSON, with the status of their Code.
+func (o *ServerOptions) ServeConnect(w http.ResponseWriter, r *http.Request, get bool, in proto.Message, call func(ctx context.Context) (proto.Message, error)) {
+	if r.Method != http.MethodPost && !(get && r.Method == http.MethodGet) {
+		if get {
+			w.Header().Set("Allow", "GET, POST")
+		} else {
+			w.Header().Set("Allow", "POST")
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	codec, ok := connectCodec(r)
+	if !ok {
+		w.Header().Set("Accept-Post", "application/json, application/proto")
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	ctx := o.NewContext(r)
+	if _, err := connectTimeout(r); err != nil {
+		o.writeConnectError(ctx, w, err)
+		return
+	}
+	if err := o.decodeConnect(r, codec, in); err != nil {
+		o.writeConnectError(ctx, w, err)
+		return
+	}
+	o.Decoded(ctx, in)
+
+	out, err := call(ctx)
+	if err != nil {
+		o.writeConnectError(ctx, w, err)
+		return
+	}
+	var data []byte
+	if codec == connectProto {
+		data, err = proto.Marshal(out)
+	} else {
+		data, err = protojson.Marshal(out)
+	}
+	if err != nil {
+		o.writeConnectError(ctx, w, Errorf(CodeInternal, "marshal response: %v", err))
+		return
+	}
+	writeConnectMetadata(ctx, w)
+	w.Header().Set("Content-Type", "application/"+codec)
+	o.writeBody(ctx, w, http.StatusOK, data)
+}
+
+// connectCodec returns the codec of the request, from its Content-Type or from the encoding query parameter of a GET.
+func connectCodec(r *http.Request) (string, bool) {
+	if r.Method == http.MethodGet {
+		switch enc := r.URL.Query().Get("encoding"); enc {
+		case connectJSON, connectProto:
+			return enc, true
+		}
+		return "", false
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		return connectJSON, true
+	case "application/proto":
+		return connectProto, true
+	}
+	return "", false
+}
+
+// connectTimeout returns the timeout in the Connect-Timeout-Ms header, or 0 if there is none.
+func connectTimeout(r *http.Request) (time.Duration, error) {
+	v := r.Header.Get("Connect-Timeout-Ms")
+	if v == "" {
+		return 0, nil
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || ms <= 0 || len(v) > 10 {
+		return 0, Errorf(CodeInvalidArgument, "invalid Connect-Timeout-Ms %q", v)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// decodeConnect reads the message of the request into in with codec.
+func (o *ServerOptions) decodeConnect(r *http.Request, codec string, in proto.Message) error {
+	if v := r.Header.Get("Connect-Protocol-Version"); v != "" && v != "1" {
+		return Errorf(CodeInvalidArgument, "unsupported Connect-Protocol-Version %q", v)
+	}
+
+	var data []byte
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		if v := query.Get("connect"); v != "" && v != "v1" {
+			return Errorf(CodeInvalidArgument, "unsupported connect query parameter %q", v)
+		}
+		if c := query.Get("compression"); c != "" && c != "identity" {
+			return Errorf(CodeUnimplemented, "unsupported compression %q", c)
+		}
+		data = []byte(query.Get("message"))
+		if query.Get("base64") == "1" {
+			var err error
+			data, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(string(data), "="))
+			if err != nil {
+				return Errorf(CodeInvalidArgument, "decode message: %v", err)
+			}
+		}
+	} else {
+		if err := o.requestBody(r); err != nil {
+			return err
+		}
+		var err error
+		data, err = io.ReadAll(r.Body)
+		if err != nil {
+			return Errorf(CodeOf(err), "read request: %w", err)
+		}
+	}
+
+	var err error
+	switch {
+	case codec == connectProto:
+		err = proto.Unmarshal(data, in)
+	case len(data) > 0:
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, in)
+	}
+	if err != nil {
+		return Errorf(CodeInvalidArgument, "unmarshal request: %v", err)
+	}
+	return nil
+}
+
+// writeConnectError writes err as a Connect error, see https://connectrpc.com/docs/protocol#error-end-stream.
+func (o *ServerOptions) writeConnectError(ctx context.Context, w http.ResponseWriter, err error) {
+	setCallError(ctx, err)
+	code := CodeOf(err)
+	if code == CodeOK {
+		code = CodeUnknown
+	}
+	writeConnectMetadata(ctx, w)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code.HTTPStatus())
+	jenc := json.NewEncoder(w)
+	jenc.SetEscapeHTML(false)
+	jenc.Encode(map[string]string{
+		"code":    code.String(),
+		"message": messageOf(err),
+	})
+}
+
+// writeConnectMetadata copies the metadata in ctx into the headers of w.
+// Unary Connect responses have no trailers, so the trailer metadata is sent as headers prefixed with "Trailer-".
+func writeConnectMetadata(ctx context.Context, w http.ResponseWriter) {
+	ss := serverStreamFromContext(ctx)
+	if ss == nil {
+		return
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	h := w.Header()
+	for k, vs := range ss.header {
+		k = http.CanonicalHeaderKey(k)
+		h.Del(k)
+		for _, v := range vs {
+			h.Add(k, v)
+		}
+	}
+	for k, vs := range ss.trailer {
+		k = http.CanonicalHeaderKey("Trailer-" + k)
+		for _, v := range vs {
+			h.Add(k, v)
+		}
+	}
+}
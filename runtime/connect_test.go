@@ -0,0 +1,175 @@
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// echo serves r with ServeConnect over a method echoing its wrapperspb.StringValue request.
+func echo(r *http.Request, get bool, err error) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	in := &wrapperspb.StringValue{}
+	NewServerOptions().ServeConnect(w, r, get, in, func(ctx context.Context) (proto.Message, error) {
+		if err != nil {
+			return nil, err
+		}
+		SetTrailer(ctx, Pairs("x-echo", in.Value))
+		if _, ok := ctx.Deadline(); ok {
+			SetHeader(ctx, Pairs("x-deadline", "1"))
+		}
+		return in, nil
+	})
+	return w
+}
+
+func TestServeConnect(t *testing.T) {
+	binary, _ := proto.Marshal(wrapperspb.String("hi"))
+	for _, spec := range []struct {
+		name        string
+		req         func() *http.Request
+		get         bool
+		err         error
+		wantStatus  int
+		wantType    string
+		wantBody    string
+		wantHeaders map[string]string
+	}{
+		{
+			name: "json",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", strings.NewReader(`"hi"`))
+				r.Header.Set("Content-Type", "application/json; charset=utf-8")
+				r.Header.Set("Connect-Protocol-Version", "1")
+				return r
+			},
+			wantStatus:  http.StatusOK,
+			wantType:    "application/json",
+			wantBody:    `"hi"`,
+			wantHeaders: map[string]string{"Trailer-X-Echo": "hi"},
+		},
+		{
+			name: "proto",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", strings.NewReader(string(binary)))
+				r.Header.Set("Content-Type", "application/proto")
+				r.Header.Set("Connect-Timeout-Ms", "1000")
+				return r
+			},
+			wantStatus:  http.StatusOK,
+			wantType:    "application/proto",
+			wantBody:    string(binary),
+			wantHeaders: map[string]string{"X-Deadline": "1"},
+		},
+		{
+			name: "get",
+			req: func() *http.Request {
+				q := url.Values{"encoding": {"proto"}, "base64": {"1"}, "message": {base64.URLEncoding.EncodeToString(binary)}}
+				return httptest.NewRequest(http.MethodGet, "/echo.v1.Echo/Echo?"+q.Encode(), nil)
+			},
+			get:        true,
+			wantStatus: http.StatusOK,
+			wantType:   "application/proto",
+			wantBody:   string(binary),
+		},
+		{
+			name: "get not allowed",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/echo.v1.Echo/Echo?encoding=json&message=%22hi%22", nil)
+			},
+			wantStatus:  http.StatusMethodNotAllowed,
+			wantHeaders: map[string]string{"Allow": "POST"},
+		},
+		{
+			name: "unsupported media type",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", strings.NewReader(`hi`))
+				r.Header.Set("Content-Type", "text/plain")
+				return r
+			},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "invalid timeout",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", strings.NewReader(`"hi"`))
+				r.Header.Set("Content-Type", "application/json")
+				r.Header.Set("Connect-Timeout-Ms", "-1")
+				return r
+			},
+			wantStatus: http.StatusBadRequest,
+			wantType:   "application/json",
+			wantBody:   `{"code":"invalid_argument","message":"invalid Connect-Timeout-Ms \"-1\""}`,
+		},
+		{
+			name: "error",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", strings.NewReader(`"hi"`))
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			},
+			err:        NewError(CodeNotFound, "no echo"),
+			wantStatus: http.StatusNotFound,
+			wantType:   "application/json",
+			wantBody:   `{"code":"not_found","message":"no echo"}`,
+		},
+		{
+			name: "deadline",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", strings.NewReader(`"hi"`))
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			},
+			err:        context.DeadlineExceeded,
+			wantStatus: http.StatusGatewayTimeout,
+			wantType:   "application/json",
+			wantBody:   `{"code":"deadline_exceeded","message":"context deadline exceeded"}`,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			w := echo(spec.req(), spec.get, spec.err)
+			if w.Code != spec.wantStatus {
+				t.Fatalf("status = %d; want %d; body %q", w.Code, spec.wantStatus, w.Body.String())
+			}
+			if spec.wantType != "" {
+				if got := w.Header().Get("Content-Type"); got != spec.wantType {
+					t.Errorf("Content-Type = %q; want %q", got, spec.wantType)
+				}
+				if got := strings.TrimSuffix(w.Body.String(), "\n"); got != spec.wantBody {
+					t.Errorf("body = %q; want %q", got, spec.wantBody)
+				}
+			}
+			for k, want := range spec.wantHeaders {
+				if got := w.Header().Get(k); got != want {
+					t.Errorf("header %s = %q; want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConnectTimeoutExpires(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", strings.NewReader(`"hi"`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Connect-Timeout-Ms", "1")
+	w := httptest.NewRecorder()
+	NewServerOptions().ServeConnect(w, r, false, &wrapperspb.StringValue{}, func(ctx context.Context) (proto.Message, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return wrapperspb.String("late"), nil
+		}
+	})
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d; want %d", w.Code, http.StatusGatewayTimeout)
+	}
+}
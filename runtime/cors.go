@@ -0,0 +1,137 @@
+package runtime
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS is the cross-origin resource sharing policy of the generated handlers, see https://fetch.spec.whatwg.org/#http-cors-protocol.
+type CORS struct {
+	// AllowedOrigins lists the origins allowed to call the service, e.g. "https://app.example.com".
+	// "*" allows every origin and an entry such as "https://*.example.com" allows its subdomains.
+	AllowedOrigins []string
+	// AllowedHeaders lists the request headers a preflight request may ask for.
+	// When empty, the headers asked for are allowed, so that Content-Type or Connect-Protocol-Version need not be listed.
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers made available to scripts, such as those set with SetHeader.
+	ExposedHeaders []string
+	// AllowCredentials allows requests with cookies or HTTP authentication.
+	// The origin of the request is then sent back rather than "*".
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the result of a preflight request, not sent when zero.
+	MaxAge time.Duration
+}
+
+// WithCORS makes the generated handlers answer cross-origin requests as allowed by c,
+// and RegisterHttpServer register the OPTIONS preflight handlers of every path of the service.
+func WithCORS(c CORS) ServerOption {
+	return func(o *ServerOptions) {
+		o.cors = &c
+	}
+}
+
+// CORS returns h wrapped to set the CORS response headers of the requests from allowed origins,
+// it returns h unchanged when WithCORS was not given. pattern is returned as is, so that the result of a
+// generated XxxHandler function can be passed through CORS to Handle.
+func (o *ServerOptions) CORS(pattern string, h http.Handler) (string, http.Handler) {
+	if o.cors == nil {
+		return pattern, h
+	}
+	return pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if o.cors.allowOrigin(w, r) && len(o.cors.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(o.cors.ExposedHeaders, ", "))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// HandlePreflight registers on mux the OPTIONS handler of path, which is served with methods.
+// The handler answers the preflight requests from allowed origins, and the other OPTIONS requests with the Allow header.
+// Nothing is registered when WithCORS was not given.
+func (o *ServerOptions) HandlePreflight(mux interface{ Handle(string, http.Handler) }, path string, methods ...string) {
+	if o.cors == nil {
+		return
+	}
+	allow := strings.Join(append(slices.Clone(methods), http.MethodOptions), ", ")
+	mux.Handle(http.MethodOptions+" "+path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.Header.Get("Access-Control-Request-Method")
+		if method == "" {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		if !slices.Contains(methods, method) || !o.cors.allowHeaders(r) || !o.cors.allowOrigin(w, r) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Methods", method)
+		if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+		}
+		if o.cors.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(o.cors.MaxAge/time.Second)))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+// allowOrigin sets the Access-Control-Allow-Origin header when the origin of r is allowed, and reports whether it is.
+func (c *CORS) allowOrigin(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	w.Header().Add("Vary", "Origin")
+	allowed, wildcard := false, false
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			allowed, wildcard = true, true
+			break
+		}
+		if prefix, suffix, ok := strings.Cut(o, "*"); ok {
+			if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				allowed = true
+				break
+			}
+			continue
+		}
+		if strings.EqualFold(o, origin) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return false
+	}
+	if wildcard && !c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	return true
+}
+
+// allowHeaders reports whether every header asked for by the preflight request r is allowed.
+func (c *CORS) allowHeaders(r *http.Request) bool {
+	if len(c.AllowedHeaders) == 0 {
+		return true
+	}
+	for _, h := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if !slices.ContainsFunc(c.AllowedHeaders, func(a string) bool { return a == "*" || strings.EqualFold(a, h) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+	for _, spec := range []struct {
+		name        string
+		cors        CORS
+		method      string
+		headers     map[string]string
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:       "preflight",
+			cors:       CORS{AllowedOrigins: []string{"https://*.example.com"}, MaxAge: time.Hour},
+			method:     http.MethodOptions,
+			headers:    map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "PUT", "Access-Control-Request-Headers": "content-type"},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "PUT",
+				"Access-Control-Allow-Headers": "content-type",
+				"Access-Control-Max-Age":       "3600",
+			},
+		},
+		{
+			name:        "preflight method not allowed",
+			cors:        CORS{AllowedOrigins: []string{"*"}},
+			method:      http.MethodOptions,
+			headers:     map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "DELETE"},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:        "preflight header not allowed",
+			cors:        CORS{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"Content-Type"}},
+			method:      http.MethodOptions,
+			headers:     map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "GET", "Access-Control-Request-Headers": "content-type, x-secret"},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:        "options",
+			cors:        CORS{AllowedOrigins: []string{"*"}},
+			method:      http.MethodOptions,
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Allow": "GET, PUT, OPTIONS"},
+		},
+		{
+			name:        "request",
+			cors:        CORS{AllowedOrigins: []string{"*"}, ExposedHeaders: []string{"X-Request-Id"}},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://app.example.com"},
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "*", "Access-Control-Expose-Headers": "X-Request-Id", "Vary": "Origin"},
+		},
+		{
+			name:        "request with credentials",
+			cors:        CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://app.example.com"},
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "https://app.example.com", "Access-Control-Allow-Credentials": "true"},
+		},
+		{
+			name:        "origin not allowed",
+			cors:        CORS{AllowedOrigins: []string{"https://app.example.com"}},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://evil.example.org"},
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			o := NewServerOptions(WithCORS(spec.cors))
+			mux.Handle(o.CORS("GET /v1/books/{id}", ok))
+			mux.Handle(o.CORS("PUT /v1/books/{id}", ok))
+			o.HandlePreflight(mux, "/v1/books/{id}", "GET", "PUT")
+
+			r := httptest.NewRequest(spec.method, "/v1/books/1", nil)
+			for k, v := range spec.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != spec.wantStatus {
+				t.Fatalf("status = %d; want %d", w.Code, spec.wantStatus)
+			}
+			for k, want := range spec.wantHeaders {
+				if got := w.Header().Get(k); got != want {
+					t.Errorf("header %s = %q; want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	o := NewServerOptions()
+	mux.Handle(o.CORS("GET /v1/books/{id}", http.NotFoundHandler()))
+	o.HandlePreflight(mux, "/v1/books/{id}", "GET")
+
+	r := httptest.NewRequest(http.MethodOptions, "/v1/books/1", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d; want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,70 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WithTimeout limits how long every method may take, as the timeout option of a method does:
+// the context passed to the implementation expires after d, or sooner when the request asks for it.
+func WithTimeout(d time.Duration) ServerOption {
+	return func(o *ServerOptions) {
+		o.CapTimeout(d)
+	}
+}
+
+// CapTimeout lowers the timeout of the requests to d, unless it is lower already.
+// Generated handlers call it with the timeout option of their method.
+func (o *ServerOptions) CapTimeout(d time.Duration) {
+	if d > 0 && (o.timeout <= 0 || d < o.timeout) {
+		o.timeout = d
+	}
+}
+
+// withDeadline returns ctx expiring after the timeout asked for by r, capped by the timeout of the method.
+// Invalid timeout headers are ignored here, the protocols that define them report them as errors.
+// The context is released along with the one of r when the handler returns.
+func (o *ServerOptions) withDeadline(ctx context.Context, r *http.Request) context.Context {
+	timeout, _ := requestTimeout(r)
+	if o.timeout > 0 && (timeout <= 0 || o.timeout < timeout) {
+		timeout = o.timeout
+	}
+	if timeout <= 0 {
+		return ctx
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	context.AfterFunc(r.Context(), cancel)
+	return ctx
+}
+
+// requestTimeout returns the timeout asked for by r in its grpc-timeout, Connect-Timeout-Ms or X-Request-Timeout header,
+// or 0 if there is none.
+func requestTimeout(r *http.Request) (time.Duration, error) {
+	if v := r.Header.Get("Grpc-Timeout"); v != "" {
+		return grpcTimeout(v)
+	}
+	if r.Header.Get("Connect-Timeout-Ms") != "" {
+		return connectTimeout(r)
+	}
+	if v := r.Header.Get("X-Request-Timeout"); v != "" {
+		return requestTimeoutHeader(v)
+	}
+	return 0, nil
+}
+
+// requestTimeoutHeader parses the value of a X-Request-Timeout header, a number of seconds such as "2.5"
+// or a duration with its unit such as "500ms".
+func requestTimeoutHeader(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		var s float64
+		s, err = strconv.ParseFloat(v, 64)
+		d = time.Duration(s * float64(time.Second))
+	}
+	if err != nil || d <= 0 {
+		return 0, Errorf(CodeInvalidArgument, "invalid X-Request-Timeout %q", v)
+	}
+	return d, nil
+}
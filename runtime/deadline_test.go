@@ -0,0 +1,76 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewContextDeadline(t *testing.T) {
+	for _, spec := range []struct {
+		name    string
+		header  string
+		value   string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{name: "none"},
+		{name: "grpc-timeout", header: "Grpc-Timeout", value: "2S", want: 2 * time.Second},
+		{name: "Connect-Timeout-Ms", header: "Connect-Timeout-Ms", value: "1500", want: 1500 * time.Millisecond},
+		{name: "X-Request-Timeout seconds", header: "X-Request-Timeout", value: "2.5", want: 2500 * time.Millisecond},
+		{name: "X-Request-Timeout duration", header: "X-Request-Timeout", value: "300ms", want: 300 * time.Millisecond},
+		{name: "invalid header", header: "X-Request-Timeout", value: "soon"},
+		{name: "method timeout", timeout: time.Second, want: time.Second},
+		{name: "capped", header: "X-Request-Timeout", value: "1m", timeout: time.Second, want: time.Second},
+		{name: "shorter than the method", header: "X-Request-Timeout", value: "1s", timeout: time.Minute, want: time.Second},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/books/1", nil)
+			if spec.header != "" {
+				r.Header.Set(spec.header, spec.value)
+			}
+			o := NewServerOptions()
+			o.CapTimeout(spec.timeout)
+			deadline, ok := o.NewContext(r).Deadline()
+			if spec.want == 0 {
+				if ok {
+					t.Errorf("context has a deadline in %v, want none", time.Until(deadline))
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("context has no deadline, want one in %v", spec.want)
+			}
+			if got := time.Until(deadline); got > spec.want || got < spec.want-time.Second/10 {
+				t.Errorf("context deadline is in %v, want %v", got, spec.want)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	o := NewServerOptions(WithTimeout(time.Minute))
+	o.CapTimeout(time.Hour)
+	if o.timeout != time.Minute {
+		t.Errorf("timeout = %v, want the lower %v", o.timeout, time.Minute)
+	}
+}
+
+func TestDeadlineExceeded(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/v1/books/1", nil)
+	r.Header.Set("X-Request-Timeout", "1ms")
+	w := httptest.NewRecorder()
+	o := NewServerOptions()
+	ctx := o.NewContext(r)
+	<-ctx.Done()
+	o.WriteError(ctx, w, ctx.Err())
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d; want %d", w.Code, http.StatusGatewayTimeout)
+	}
+	if got, want := strings.TrimSuffix(w.Body.String(), "\n"), `{"code":4,"message":"`+context.DeadlineExceeded.Error()+`"}`; got != want {
+		t.Errorf("body = %q; want %q", got, want)
+	}
+}
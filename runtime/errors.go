@@ -0,0 +1,179 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/status"
+)
+
+// Code is the status code of a failed RPC, numbered like the gRPC codes.
+// The Connect, Twirp and gRPC-Web handlers report it to clients in the encoding of their protocol.
+type Code uint32
+
+const (
+	CodeOK                 Code = 0
+	CodeCanceled           Code = 1
+	CodeUnknown            Code = 2
+	CodeInvalidArgument    Code = 3
+	CodeDeadlineExceeded   Code = 4
+	CodeNotFound           Code = 5
+	CodeAlreadyExists      Code = 6
+	CodePermissionDenied   Code = 7
+	CodeResourceExhausted  Code = 8
+	CodeFailedPrecondition Code = 9
+	CodeAborted            Code = 10
+	CodeOutOfRange         Code = 11
+	CodeUnimplemented      Code = 12
+	CodeInternal           Code = 13
+	CodeUnavailable        Code = 14
+	CodeDataLoss           Code = 15
+	CodeUnauthenticated    Code = 16
+)
+
+var codeNames = [...]string{
+	CodeOK:                 "ok",
+	CodeCanceled:           "canceled",
+	CodeUnknown:            "unknown",
+	CodeInvalidArgument:    "invalid_argument",
+	CodeDeadlineExceeded:   "deadline_exceeded",
+	CodeNotFound:           "not_found",
+	CodeAlreadyExists:      "already_exists",
+	CodePermissionDenied:   "permission_denied",
+	CodeResourceExhausted:  "resource_exhausted",
+	CodeFailedPrecondition: "failed_precondition",
+	CodeAborted:            "aborted",
+	CodeOutOfRange:         "out_of_range",
+	CodeUnimplemented:      "unimplemented",
+	CodeInternal:           "internal",
+	CodeUnavailable:        "unavailable",
+	CodeDataLoss:           "data_loss",
+	CodeUnauthenticated:    "unauthenticated",
+}
+
+// String returns the snake case name of c used by the Connect protocol, e.g. "not_found".
+func (c Code) String() string {
+	if int(c) < len(codeNames) {
+		return codeNames[c]
+	}
+	return fmt.Sprintf("code_%d", uint32(c))
+}
+
+// HTTPStatus returns the HTTP status the Connect protocol answers c with.
+func (c Code) HTTPStatus() int {
+	switch c {
+	case CodeOK:
+		return http.StatusOK
+	case CodeCanceled:
+		return 499
+	case CodeInvalidArgument, CodeFailedPrecondition, CodeOutOfRange:
+		return http.StatusBadRequest
+	case CodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeAlreadyExists, CodeAborted:
+		return http.StatusConflict
+	case CodePermissionDenied:
+		return http.StatusForbidden
+	case CodeResourceExhausted:
+		return http.StatusTooManyRequests
+	case CodeUnimplemented:
+		return http.StatusNotImplemented
+	case CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case CodeUnauthenticated:
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is an error carrying a Code. Implementations return it to fail with a code other than unknown.
+type Error struct {
+	code Code
+	msg  string
+	err  error
+	meta map[string]string
+}
+
+// NewError returns an error with code and message.
+func NewError(code Code, msg string) *Error {
+	return &Error{code: code, msg: msg}
+}
+
+// Errorf returns an error with code and a message formatted like fmt.Errorf.
+// The error wraps the %w operands.
+func Errorf(code Code, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{code: code, msg: err.Error(), err: err}
+}
+
+func (e *Error) Error() string {
+	return e.code.String() + ": " + e.msg
+}
+
+// Code returns the code of e.
+func (e *Error) Code() Code {
+	return e.code
+}
+
+// Message returns the message of e, without the code.
+func (e *Error) Message() string {
+	return e.msg
+}
+
+// WithMeta adds the key-value pair to the metadata of e, which Twirp clients receive as the error meta.
+func (e *Error) WithMeta(key, value string) *Error {
+	if e.meta == nil {
+		e.meta = map[string]string{}
+	}
+	e.meta[key] = value
+	return e
+}
+
+// Meta returns the metadata of e.
+func (e *Error) Meta() map[string]string {
+	return e.meta
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// CodeOf returns the code of err: the code of the *Error or of the gRPC status in its chain, deadline_exceeded
+// or canceled for the context errors, resource_exhausted for a request body over WithMaxBodySize, and unknown otherwise.
+func CodeOf(err error) Code {
+	var e *Error
+	var st interface{ GRPCStatus() *status.Status }
+	var maxBytes *http.MaxBytesError
+	switch {
+	case err == nil:
+		return CodeOK
+	case errors.As(err, &e):
+		return e.code
+	case errors.As(err, &st):
+		return Code(st.GRPCStatus().Code())
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.As(err, &maxBytes):
+		return CodeResourceExhausted
+	}
+	return CodeUnknown
+}
+
+// messageOf returns the message of err reported to clients, which omits the code of an *Error or a gRPC status.
+func messageOf(err error) string {
+	var e *Error
+	var st interface{ GRPCStatus() *status.Status }
+	switch {
+	case errors.As(err, &e):
+		return e.msg
+	case errors.As(err, &st):
+		return st.GRPCStatus().Message()
+	}
+	return err.Error()
+}
@@ -0,0 +1,166 @@
+package runtime
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithETags makes the generated REST handlers support conditional requests with the etag field of AIP-154.
+//
+// Successful GET responses get an ETag header, the etag field of the response message or else a weak hash of the
+// response body, and are answered with 304 Not Modified when it matches the If-None-Match header.
+// The tag of a compressed response is weak, as its bytes differ from the uncompressed ones.
+// Other methods copy the entity tag of the If-Match header into the etag field of the request, the top-level one or
+// the one of the resource it carries. They fail with 412 Precondition Failed when the request has no etag field or
+// has another etag, and when the implementation fails with aborted, the code of AIP-154 for a stale etag.
+func WithETags() ServerOption {
+	return func(o *ServerOptions) {
+		o.etags = true
+	}
+}
+
+// conditional holds the conditional headers of a request served with WithETags.
+type conditional struct {
+	method      string
+	ifNoneMatch string
+	ifMatch     string
+}
+
+type conditionalKey struct{}
+
+func (o *ServerOptions) withConditional(ctx context.Context, r *http.Request) context.Context {
+	if !o.etags {
+		return ctx
+	}
+	return context.WithValue(ctx, conditionalKey{}, &conditional{
+		method:      r.Method,
+		ifNoneMatch: r.Header.Get("If-None-Match"),
+		ifMatch:     strings.TrimSpace(r.Header.Get("If-Match")),
+	})
+}
+
+func conditionalFromContext(ctx context.Context) *conditional {
+	c, _ := ctx.Value(conditionalKey{}).(*conditional)
+	return c
+}
+
+// IfMatch copies the entity tag of the If-Match header of the request of ctx into the etag field of in.
+// It does nothing without WithETags, without the header, or for If-Match: *.
+func (o *ServerOptions) IfMatch(ctx context.Context, in proto.Message) error {
+	c := conditionalFromContext(ctx)
+	if c == nil || c.ifMatch == "" || c.ifMatch == "*" {
+		return nil
+	}
+	if strings.Contains(c.ifMatch, ",") {
+		return NewError(CodeInvalidArgument, "If-Match must have a single entity tag")
+	}
+	m, fd := etagField(in.ProtoReflect())
+	if fd == nil {
+		return NewError(CodeFailedPrecondition, "the request has no etag for If-Match")
+	}
+	// The weak tag of a compressed response stands for the same etag.
+	etag := unquoteETag(strings.TrimPrefix(c.ifMatch, "W/"))
+	if current := m.Get(fd).String(); current != "" && current != etag {
+		return Errorf(CodeFailedPrecondition, "etag %q does not match If-Match", current)
+	}
+	m.Set(fd, protoreflect.ValueOfString(etag))
+	return nil
+}
+
+// etagField returns the etag string field of m, or the one of the first singular message field of m holding one.
+// The message is made mutable so that the field can be set.
+func etagField(m protoreflect.Message) (protoreflect.Message, protoreflect.FieldDescriptor) {
+	fields := m.Descriptor().Fields()
+	if fd := fields.ByName("etag"); isETagField(fd) {
+		return m, fd
+	}
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			continue
+		}
+		if etag := fd.Message().Fields().ByName("etag"); isETagField(etag) {
+			return m.Mutable(fd).Message(), etag
+		}
+	}
+	return nil, nil
+}
+
+func isETagField(fd protoreflect.FieldDescriptor) bool {
+	return fd != nil && fd.Kind() == protoreflect.StringKind && !fd.IsList()
+}
+
+// preconditionFailed reports whether err is answered with 412 for the request of ctx.
+func preconditionFailed(ctx context.Context, err error) bool {
+	c := conditionalFromContext(ctx)
+	if c == nil || c.ifMatch == "" {
+		return false
+	}
+	code := CodeOf(err)
+	return code == CodeFailedPrecondition || code == CodeAborted
+}
+
+// notModified sets the ETag header of a successful GET response whose message is resp, a proto.Message or nil,
+// and whose body is data. It reports whether the response was answered with 304 Not Modified instead.
+func (o *ServerOptions) notModified(ctx context.Context, w http.ResponseWriter, code int, resp any, data []byte) bool {
+	c := conditionalFromContext(ctx)
+	if c == nil || (c.method != http.MethodGet && c.method != http.MethodHead) || code < 200 || code > 299 {
+		return false
+	}
+	var etag string
+	if msg, ok := resp.(proto.Message); ok && msg != nil {
+		if m := msg.ProtoReflect(); m.IsValid() {
+			if fd := m.Descriptor().Fields().ByName("etag"); isETagField(fd) && m.Get(fd).String() != "" {
+				etag = quoteETag(m.Get(fd).String())
+			}
+		}
+	}
+	if etag == "" {
+		sum := sha256.Sum256(data)
+		etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+	if o.bodyCompressor(ctx, data) != nil && !strings.HasPrefix(etag, "W/") {
+		// A strong tag would identify the bytes of the uncompressed body, see RFC 9110 section 8.8.3.3.
+		etag = "W/" + etag
+	}
+	w.Header().Set("ETag", etag)
+	if !etagMatches(c.ifNoneMatch, etag) {
+		return false
+	}
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether one of the entity tags in header, e.g. an If-None-Match header, weakly matches etag.
+func etagMatches(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteETag returns the entity tag of the value of an etag field, which is used as is when already quoted.
+func quoteETag(v string) string {
+	if strings.HasSuffix(v, `"`) && (strings.HasPrefix(v, `"`) || strings.HasPrefix(v, `W/"`)) && len(v) > 1 {
+		return v
+	}
+	return `"` + v + `"`
+}
+
+// unquoteETag returns the etag field value of a strong entity tag, without its quotes. Weak tags are kept as is.
+func unquoteETag(t string) string {
+	if len(t) >= 2 && strings.HasPrefix(t, `"`) && strings.HasSuffix(t, `"`) {
+		return t[1 : len(t)-1]
+	}
+	return t
+}
@@ -0,0 +1,176 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// etagMessages returns the descriptors of the shelf.v1 messages with AIP-154 etag fields.
+func etagMessages(t *testing.T) protoreflect.MessageDescriptors {
+	t.Helper()
+	return testMessages(t, `
+syntax: "proto3"
+name: "shelf/v1/shelf.proto"
+package: "shelf.v1"
+message_type: {
+	name: "Shelf"
+	field: { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+	field: { name: "etag" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "etag" }
+}
+message_type: {
+	name: "UpdateShelfRequest"
+	field: { name: "shelf" number: 1 type: TYPE_MESSAGE type_name: ".shelf.v1.Shelf" label: LABEL_OPTIONAL json_name: "shelf" }
+}
+message_type: {
+	name: "DeleteShelfRequest"
+	field: { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+	field: { name: "etag" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "etag" }
+}
+message_type: {
+	name: "ListShelvesRequest"
+	field: { name: "page_size" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "pageSize" }
+}
+`)
+}
+
+func TestETagGet(t *testing.T) {
+	shelf := newTestMessage(t, etagMessages(t).ByName("Shelf"), `name: "shelves/1" etag: "v1"`)
+	for _, spec := range []struct {
+		name           string
+		opts           []ServerOption
+		method         string
+		ifNoneMatch    string
+		acceptEncoding string
+		resp           any
+		wantETag       string
+		wantStatus     int
+	}{
+		{name: "etag field", opts: []ServerOption{WithETags()}, method: http.MethodGet, resp: shelf, wantETag: `"v1"`, wantStatus: http.StatusOK},
+		{name: "not modified", opts: []ServerOption{WithETags()}, method: http.MethodGet, ifNoneMatch: `"v0", W/"v1"`, resp: shelf, wantETag: `"v1"`, wantStatus: http.StatusNotModified},
+		{name: "any", opts: []ServerOption{WithETags()}, method: http.MethodGet, ifNoneMatch: "*", resp: shelf, wantETag: `"v1"`, wantStatus: http.StatusNotModified},
+		{name: "hash", opts: []ServerOption{WithETags()}, method: http.MethodGet, resp: wrapperspb.String("Dune"), wantETag: `W/"`, wantStatus: http.StatusOK},
+		{name: "compressed", opts: []ServerOption{WithETags(), WithCompressMinSize(1)}, method: http.MethodGet, acceptEncoding: "gzip", resp: shelf, wantETag: `W/"v1"`, wantStatus: http.StatusOK},
+		{name: "compressed not modified", opts: []ServerOption{WithETags(), WithCompressMinSize(1)}, method: http.MethodGet, ifNoneMatch: `"v1"`, acceptEncoding: "gzip", resp: shelf, wantETag: `W/"v1"`, wantStatus: http.StatusNotModified},
+		{name: "not a GET", opts: []ServerOption{WithETags()}, method: http.MethodPost, ifNoneMatch: "*", resp: shelf, wantStatus: http.StatusOK},
+		{name: "disabled", method: http.MethodGet, ifNoneMatch: "*", resp: shelf, wantStatus: http.StatusOK},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			o := NewServerOptions(spec.opts...)
+			r := httptest.NewRequest(spec.method, "/v1/shelves/1", nil)
+			if spec.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", spec.ifNoneMatch)
+			}
+			r.Header.Set("Accept-Encoding", spec.acceptEncoding)
+			w := httptest.NewRecorder()
+			o.WriteResponse(o.NewContext(r), w, http.StatusOK, spec.resp)
+
+			if w.Code != spec.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, spec.wantStatus)
+			}
+			if got := w.Header().Get("ETag"); !strings.HasPrefix(got, spec.wantETag) || (spec.wantETag == "") != (got == "") {
+				t.Errorf("ETag = %q, want %q", got, spec.wantETag)
+			}
+			if spec.wantStatus == http.StatusNotModified && w.Body.Len() > 0 {
+				t.Errorf("304 response has body %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestETagHash(t *testing.T) {
+	o := NewServerOptions(WithETags())
+	get := func(ifNoneMatch string, data string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/icons/1", nil)
+		r.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+		o.WriteHttpBody(o.NewContext(r), w, http.StatusOK, &httpbody.HttpBody{ContentType: "image/png", Data: []byte(data)})
+		return w
+	}
+	etag := get("", "\x89PNG").Header().Get("ETag")
+	if w := get(etag, "\x89PNG"); w.Code != http.StatusNotModified {
+		t.Errorf("status with the ETag of the same body = %d, want 304", w.Code)
+	}
+	if w := get(etag, "\x89PNG2"); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("status with the ETag of another body = %d and ETag %q, want 200 and another ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	msgs := etagMessages(t)
+	for _, spec := range []struct {
+		name       string
+		message    protoreflect.Name
+		in         string
+		ifMatch    string
+		opts       []ServerOption
+		want       string
+		wantStatus int
+	}{
+		{name: "top-level", message: "DeleteShelfRequest", ifMatch: `"v1"`, want: `etag:"v1"`},
+		{name: "resource", message: "UpdateShelfRequest", in: `shelf: { name: "shelves/1" }`, ifMatch: `"v1"`, want: `shelf:{name:"shelves/1" etag:"v1"}`},
+		{name: "weak", message: "DeleteShelfRequest", ifMatch: `W/"v1"`, want: `etag:"v1"`},
+		{name: "same etag", message: "DeleteShelfRequest", in: `etag: "v1"`, ifMatch: `"v1"`, want: `etag:"v1"`},
+		{name: "other etag", message: "DeleteShelfRequest", in: `etag: "v2"`, ifMatch: `"v1"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "no etag field", message: "ListShelvesRequest", ifMatch: `"v1"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "any", message: "DeleteShelfRequest", ifMatch: "*"},
+		{name: "no header", message: "DeleteShelfRequest"},
+		{name: "disabled", message: "DeleteShelfRequest", ifMatch: `"v1"`, opts: []ServerOption{}},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			opts := spec.opts
+			if opts == nil {
+				opts = []ServerOption{WithETags()}
+			}
+			o := NewServerOptions(opts...)
+			r := httptest.NewRequest(http.MethodDelete, "/v1/shelves/1", nil)
+			if spec.ifMatch != "" {
+				r.Header.Set("If-Match", spec.ifMatch)
+			}
+			ctx := o.NewContext(r)
+			in := newTestMessage(t, msgs.ByName(spec.message), spec.in)
+			err := o.IfMatch(ctx, in)
+			if spec.wantStatus != 0 {
+				w := httptest.NewRecorder()
+				o.WriteError(ctx, w, err)
+				if w.Code != spec.wantStatus {
+					t.Errorf("IfMatch() = %v answered with %d, want %d", err, w.Code, spec.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IfMatch() failed with %v", err)
+			}
+			if got := strings.ReplaceAll(prototext.MarshalOptions{}.Format(in), " ", ""); got != strings.ReplaceAll(spec.want, " ", "") {
+				t.Errorf("IfMatch() set %s, want %s", got, spec.want)
+			}
+		})
+	}
+}
+
+func TestIfMatchAborted(t *testing.T) {
+	o := NewServerOptions(WithETags())
+	for _, spec := range []struct {
+		ifMatch    string
+		wantStatus int
+	}{
+		{ifMatch: `"v1"`, wantStatus: http.StatusPreconditionFailed},
+		{wantStatus: http.StatusConflict},
+	} {
+		r := httptest.NewRequest(http.MethodPatch, "/v1/shelves/1", nil)
+		if spec.ifMatch != "" {
+			r.Header.Set("If-Match", spec.ifMatch)
+		}
+		w := httptest.NewRecorder()
+		o.WriteError(o.NewContext(r), w, NewError(CodeAborted, "stale etag"))
+		if w.Code != spec.wantStatus {
+			t.Errorf("aborted call with If-Match %q answered with %d, want %d", spec.ifMatch, w.Code, spec.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package runtime
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DecodeUpdateBody binds the body of an update request to the resource field of in, e.g. "book", like DecodeBody:
+// the body is the resource itself, as in {"title": "Dune"}. When the update_mask field of in is left empty, it is set
+// to the paths of the fields present in the body, nested messages included: {"title": "Dune", "author": {"name":
+// "Frank Herbert"}} is an update of "title" and "author.name". Fields set to null are in the mask, to clear them.
+func (o *ServerOptions) DecodeUpdateBody(r *http.Request, in proto.Message, resource string) error {
+	m := in.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(resource))
+	if fd == nil || fd.Message() == nil {
+		return Errorf(CodeInternal, "%s has no %q message field", m.Descriptor().FullName(), resource)
+	}
+	body := m.Mutable(fd).Message()
+	var paths []string
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		if err := o.DecodeBody(r, body.Interface()); err != nil {
+			return err
+		}
+		paths = formPaths(fd.Message(), r.PostForm)
+	default:
+		if err := o.requestBody(r); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if err := decodeJSON(data, body.Interface()); err != nil {
+			return err
+		}
+		paths = jsonPaths(fd.Message(), data)
+	}
+	setUpdateMask(m, paths)
+	return nil
+}
+
+// jsonPaths returns the paths of the fields of md present in the JSON body data.
+func jsonPaths(md protoreflect.MessageDescriptor, data []byte) []string {
+	var paths []string
+	collectPaths(md, "", data, &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+// collectPaths appends to paths the fields of md present in the JSON object raw, prefixed with prefix.
+// Non-empty objects of singular message fields are walked into, other values are leaves.
+func collectPaths(md protoreflect.MessageDescriptor, prefix string, raw json.RawMessage, paths *[]string) {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) != nil {
+		return
+	}
+	for key, v := range obj {
+		fd := jsonField(md, key)
+		if fd == nil {
+			continue
+		}
+		path := prefix + string(fd.Name())
+		n := len(*paths)
+		if isNestedMessage(fd) {
+			collectPaths(fd.Message(), path+".", v, paths)
+		}
+		if len(*paths) == n {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// formPaths returns the paths of the fields of md present in the form, whose keys address nested fields
+// as "parent.child".
+func formPaths(md protoreflect.MessageDescriptor, form url.Values) []string {
+	var paths []string
+	for key := range form {
+		var path []string
+		parent := md
+		for _, name := range strings.Split(key, ".") {
+			if parent == nil {
+				break
+			}
+			fd := jsonField(parent, name)
+			if fd == nil {
+				path = nil
+				break
+			}
+			path = append(path, string(fd.Name()))
+			parent = nil
+			if isNestedMessage(fd) {
+				parent = fd.Message()
+			}
+		}
+		if len(path) > 0 {
+			paths = append(paths, strings.Join(path, "."))
+		}
+	}
+	sort.Strings(paths)
+	return slices.Compact(paths)
+}
+
+// jsonField returns the field of md a JSON key decodes into, matched against the proto name
+// like encoding/json matches the json tags of generated messages, case-insensitively.
+func jsonField(md protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	if fd := fields.ByName(protoreflect.Name(key)); fd != nil {
+		return fd
+	}
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); strings.EqualFold(string(fd.Name()), key) {
+			return fd
+		}
+	}
+	return nil
+}
+
+// isNestedMessage reports whether the paths of fd may address its own fields:
+// fd is a singular message field whose type is not a well-known type.
+func isNestedMessage(fd protoreflect.FieldDescriptor) bool {
+	return fd.Message() != nil && !fd.IsList() && !fd.IsMap() && fd.Message().ParentFile().Package() != "google.protobuf"
+}
+
+// setUpdateMask sets the update_mask FieldMask field of m to paths unless it already has paths.
+func setUpdateMask(m protoreflect.Message, paths []string) {
+	fd := m.Descriptor().Fields().ByName("update_mask")
+	if len(paths) == 0 || fd == nil || fd.Message() == nil || fd.Message().FullName() != "google.protobuf.FieldMask" {
+		return
+	}
+	pathsField := fd.Message().Fields().ByName("paths")
+	if m.Has(fd) && m.Get(fd).Message().Get(pathsField).List().Len() > 0 {
+		return
+	}
+	mask := m.NewField(fd).Message()
+	list := mask.Mutable(pathsField).List()
+	for _, p := range paths {
+		list.Append(protoreflect.ValueOfString(p))
+	}
+	m.Set(fd, protoreflect.ValueOfMessage(mask))
+}
@@ -0,0 +1,141 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// updateBookRequest returns an empty library.v1.UpdateBookRequest, an AIP-134 update request of a Book.
+func updateBookRequest(t *testing.T) *dynamicpb.Message {
+	t.Helper()
+	return dynamicpb.NewMessage(testMessages(t, `
+syntax: "proto3"
+name: "library/v1/update.proto"
+package: "library.v1"
+dependency: "google/protobuf/field_mask.proto"
+dependency: "google/protobuf/timestamp.proto"
+message_type: {
+	name: "Author"
+	field: { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+	field: { name: "born" number: 2 type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" label: LABEL_OPTIONAL json_name: "born" }
+}
+message_type: {
+	name: "Book"
+	field: { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+	field: { name: "page_count" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "pageCount" }
+	field: { name: "author" number: 3 type: TYPE_MESSAGE type_name: ".library.v1.Author" label: LABEL_OPTIONAL json_name: "author" }
+	field: { name: "tags" number: 4 type: TYPE_STRING label: LABEL_REPEATED json_name: "tags" }
+}
+message_type: {
+	name: "UpdateBookRequest"
+	field: { name: "book" number: 1 type: TYPE_MESSAGE type_name: ".library.v1.Book" label: LABEL_OPTIONAL json_name: "book" }
+	field: { name: "update_mask" number: 2 type: TYPE_MESSAGE type_name: ".google.protobuf.FieldMask" label: LABEL_OPTIONAL json_name: "updateMask" }
+}
+`).ByName("UpdateBookRequest"))
+}
+
+func TestDecodeUpdateBody(t *testing.T) {
+	for _, spec := range []struct {
+		name        string
+		contentType string
+		body        string
+		mask        string
+		want        []string
+	}{
+		{
+			name:        "top-level",
+			contentType: "application/json",
+			body:        `{"name": "books/1", "page_count": 412, "tags": []}`,
+			want:        []string{"name", "page_count", "tags"},
+		},
+		{
+			name:        "nested",
+			contentType: "application/json",
+			body:        `{"author": {"name": "Frank Herbert", "born": "1920-10-08T00:00:00Z"}}`,
+			want:        []string{"author.born", "author.name"},
+		},
+		{
+			name:        "cleared",
+			contentType: "application/json",
+			body:        `{"Author": null, "PAGE_COUNT": 0}`,
+			want:        []string{"author", "page_count"},
+		},
+		{
+			name:        "empty object",
+			contentType: "application/json",
+			body:        `{"author": {}, "unknown": 1}`,
+			want:        []string{"author"},
+		},
+		{
+			name:        "explicit mask",
+			contentType: "application/json",
+			body:        `{"name": "books/1", "page_count": 412}`,
+			mask:        `paths: "page_count"`,
+			want:        []string{"page_count"},
+		},
+		{
+			name:        "empty",
+			contentType: "application/json",
+			body:        `{}`,
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=books/1&author.name=Frank+Herbert&author.name=F.+Herbert&unknown=1",
+			want:        []string{"author.name", "name"},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			in := updateBookRequest(t)
+			if spec.mask != "" {
+				fd := in.Descriptor().Fields().ByName("update_mask")
+				if err := prototext.Unmarshal([]byte(spec.mask), in.Mutable(fd).Message().Interface()); err != nil {
+					t.Fatalf("prototext.Unmarshal() failed with %v", err)
+				}
+			}
+			r := httptest.NewRequest(http.MethodPatch, "/v1/books/1", strings.NewReader(spec.body))
+			r.Header.Set("Content-Type", spec.contentType)
+			if err := NewServerOptions().DecodeUpdateBody(r, in, "book"); err != nil {
+				t.Fatalf("DecodeUpdateBody() failed with %v", err)
+			}
+
+			var got []string
+			fd := in.Descriptor().Fields().ByName("update_mask")
+			if in.Has(fd) {
+				paths := in.Get(fd).Message().Get(fd.Message().Fields().ByName("paths")).List()
+				for i := 0; i < paths.Len(); i++ {
+					got = append(got, paths.Get(i).String())
+				}
+			}
+			if !reflect.DeepEqual(got, spec.want) {
+				t.Errorf("update mask = %q, want %q", got, spec.want)
+			}
+		})
+	}
+}
+
+func TestDecodeUpdateBodyResource(t *testing.T) {
+	// The body is decoded into the resource field, here the options of a file.
+	r := httptest.NewRequest(http.MethodPatch, "/v1/files/1", strings.NewReader(`{"java_package": "com.example.library"}`))
+	r.Header.Set("Content-Type", "application/json")
+	in := &descriptorpb.FileDescriptorProto{}
+	if err := NewServerOptions().DecodeUpdateBody(r, in, "options"); err != nil {
+		t.Fatalf("DecodeUpdateBody() failed with %v", err)
+	}
+	if got, want := in.GetOptions().GetJavaPackage(), "com.example.library"; got != want {
+		t.Errorf("options.java_package = %q, want %q", got, want)
+	}
+
+	if err := NewServerOptions().DecodeUpdateBody(r, in, "name"); CodeOf(err) != CodeInternal {
+		t.Errorf("DecodeUpdateBody() with a non-message resource failed with %v, want code %v", err, CodeInternal)
+	}
+}
@@ -0,0 +1,152 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithPartialResponses makes the generated REST handlers answer with the fields of the response listed in the fields
+// query parameter or the X-Goog-FieldMask header, e.g. "?fields=name,author.name", with FieldMask semantics.
+// Paths are made of proto or JSON field names. A path through a repeated or map message field selects the
+// fields of each of its elements. Unknown and empty paths fail the request with invalid_argument
+// before the implementation is called.
+func WithPartialResponses() ServerOption {
+	return func(o *ServerOptions) {
+		o.partialResponses = true
+	}
+}
+
+type fieldsKey struct{}
+
+func (o *ServerOptions) withFields(ctx context.Context, r *http.Request) context.Context {
+	if !o.partialResponses {
+		return ctx
+	}
+	fields := r.URL.Query().Get("fields")
+	if fields == "" {
+		fields = r.Header.Get("X-Goog-FieldMask")
+	}
+	if fields == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// CheckFields validates the fields asked for with the request of ctx against md, the descriptor of the response
+// message, so that a request with invalid fields fails before the implementation is called.
+// It does nothing without WithPartialResponses or when no fields are asked for.
+func (o *ServerOptions) CheckFields(ctx context.Context, md protoreflect.MessageDescriptor) error {
+	fields, ok := ctx.Value(fieldsKey{}).(string)
+	if !ok {
+		return nil
+	}
+	_, err := parseFields(md, fields)
+	return err
+}
+
+// fieldTree is the tree of the fields selected by a field mask, a nil subtree selects the whole field.
+type fieldTree map[protoreflect.FieldNumber]fieldTree
+
+// partialResponse returns resp pruned to the fields asked for with the request of ctx, or resp itself
+// when none are asked for or resp is not a message.
+func partialResponse(ctx context.Context, resp any) (any, error) {
+	fields, ok := ctx.Value(fieldsKey{}).(string)
+	msg, isMsg := resp.(proto.Message)
+	if !ok || !isMsg || msg == nil || !msg.ProtoReflect().IsValid() {
+		return resp, nil
+	}
+	tree, err := parseFields(msg.ProtoReflect().Descriptor(), fields)
+	if err != nil {
+		return nil, err
+	}
+	msg = proto.Clone(msg)
+	tree.prune(msg.ProtoReflect())
+	return msg, nil
+}
+
+// parseFields returns the tree of the comma-separated paths of the fields of md in fields.
+func parseFields(md protoreflect.MessageDescriptor, fields string) (fieldTree, error) {
+	tree := fieldTree{}
+	for _, path := range strings.Split(fields, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return nil, Errorf(CodeInvalidArgument, "empty path in fields %q", fields)
+		}
+		if err := tree.add(md, path); err != nil {
+			return nil, err
+		}
+	}
+	return tree, nil
+}
+
+// add adds the dot-separated path of the fields of md to t.
+func (t fieldTree) add(md protoreflect.MessageDescriptor, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		if name == "" {
+			return Errorf(CodeInvalidArgument, "empty field name in fields %q", path)
+		}
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return Errorf(CodeInvalidArgument, "unknown field %q in fields %q", name, path)
+		}
+		sub, ok := t[fd.Number()]
+		if ok && sub == nil {
+			// The whole field is already selected.
+			return nil
+		}
+		if i == len(names)-1 {
+			t[fd.Number()] = nil
+			return nil
+		}
+		md = fd.Message()
+		if fd.IsMap() {
+			md = fd.MapValue().Message()
+		}
+		if md == nil {
+			return Errorf(CodeInvalidArgument, "field %q in fields %q has no fields", name, path)
+		}
+		if !ok {
+			sub = fieldTree{}
+			t[fd.Number()] = sub
+		}
+		t = sub
+	}
+	return nil
+}
+
+// prune clears the fields of m not selected by t.
+func (t fieldTree) prune(m protoreflect.Message) {
+	var cleared []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := t[fd.Number()]
+		switch {
+		case !ok:
+			cleared = append(cleared, fd)
+		case sub == nil:
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				sub.prune(list.Get(i).Message())
+			}
+		case fd.IsMap():
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				sub.prune(v.Message())
+				return true
+			})
+		default:
+			sub.prune(v.Message())
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
@@ -0,0 +1,122 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestPartialResponse(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("library.proto"),
+		Package: proto.String("library.v1"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Book"), Field: []*descriptorpb.FieldDescriptorProto{{Name: proto.String("title")}}},
+			{Name: proto.String("Shelf")},
+		},
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("example.com/library"), JavaPackage: proto.String("com.example")},
+	}
+	for _, spec := range []struct {
+		name       string
+		opts       []ServerOption
+		fields     string
+		header     string
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "fields",
+			opts:       []ServerOption{WithPartialResponses()},
+			fields:     "name,options.go_package",
+			wantStatus: http.StatusOK,
+			want:       `{"name":"library.proto","options":{"go_package":"example.com/library"}}`,
+		},
+		{
+			name:       "repeated",
+			opts:       []ServerOption{WithPartialResponses()},
+			fields:     "message_type.name",
+			wantStatus: http.StatusOK,
+			want:       `{"message_type":[{"name":"Book"},{"name":"Shelf"}]}`,
+		},
+		{
+			name:       "header with JSON names",
+			opts:       []ServerOption{WithPartialResponses()},
+			header:     "package, options.goPackage, options",
+			wantStatus: http.StatusOK,
+			want:       `{"package":"library.v1","options":{"java_package":"com.example","go_package":"example.com/library"}}`,
+		},
+		{
+			name:       "unknown field",
+			opts:       []ServerOption{WithPartialResponses()},
+			fields:     "name,options.title",
+			wantStatus: http.StatusBadRequest,
+			want:       `{"code":3,"message":"invalid_argument: unknown field \"title\" in fields \"options.title\""}`,
+		},
+		{
+			name:       "empty path",
+			opts:       []ServerOption{WithPartialResponses()},
+			fields:     "name,",
+			wantStatus: http.StatusBadRequest,
+			want:       `{"code":3,"message":"invalid_argument: empty path in fields \"name,\""}`,
+		},
+		{
+			name:       "empty field name",
+			opts:       []ServerOption{WithPartialResponses()},
+			fields:     "options..go_package",
+			wantStatus: http.StatusBadRequest,
+			want:       `{"code":3,"message":"invalid_argument: empty field name in fields \"options..go_package\""}`,
+		},
+		{
+			name:       "disabled",
+			fields:     "name",
+			wantStatus: http.StatusOK,
+			want:       `"package":"library.v1"`,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			o := NewServerOptions(spec.opts...)
+			r := httptest.NewRequest(http.MethodGet, "/v1/files?fields="+url.QueryEscape(spec.fields), nil)
+			if spec.header != "" {
+				r.Header.Set("X-Goog-FieldMask", spec.header)
+			}
+			w := httptest.NewRecorder()
+			o.WriteResponse(o.NewContext(r), w, http.StatusOK, file)
+
+			if w.Code != spec.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, spec.wantStatus)
+			}
+			if !strings.Contains(w.Body.String(), spec.want) {
+				t.Errorf("body = %s, want %s", w.Body.String(), spec.want)
+			}
+			if file.GetMessageType()[0].GetField() == nil {
+				t.Errorf("WriteResponse() pruned the response of the implementation")
+			}
+		})
+	}
+}
+
+func TestCheckFields(t *testing.T) {
+	md := (&descriptorpb.FileDescriptorProto{}).ProtoReflect().Descriptor()
+	for _, spec := range []struct {
+		opts     []ServerOption
+		fields   string
+		wantCode Code
+	}{
+		{opts: []ServerOption{WithPartialResponses()}, fields: "name,options.go_package"},
+		{opts: []ServerOption{WithPartialResponses()}, fields: "name,title", wantCode: CodeInvalidArgument},
+		{opts: []ServerOption{WithPartialResponses()}, fields: ",name", wantCode: CodeInvalidArgument},
+		{opts: []ServerOption{WithPartialResponses()}},
+		{fields: "title"},
+	} {
+		o := NewServerOptions(spec.opts...)
+		r := httptest.NewRequest(http.MethodPost, "/v1/files?fields="+url.QueryEscape(spec.fields), nil)
+		if err := o.CheckFields(o.NewContext(r), md); CodeOf(err) != spec.wantCode {
+			t.Errorf("CheckFields(%q) = %v, want code %v", spec.fields, err, spec.wantCode)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// gRPC-Web frame flags, see https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md.
+const (
+	grpcWebDataFrame    byte = 0x00
+	grpcWebCompressed   byte = 0x01
+	grpcWebTrailerFrame byte = 0x80
+)
+
+// IsGRPCWeb reports whether r is a gRPC-Web request.
+func IsGRPCWeb(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web")
+}
+
+// GRPCWebOr returns a handler serving the gRPC-Web requests with grpcWeb and the other requests with h,
+// for protocols sharing the /package.Service/Method path of gRPC-Web such as Connect.
+func GRPCWebOr(grpcWeb, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsGRPCWeb(r) {
+			grpcWeb.ServeHTTP(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ServeGRPCWeb serves r with the gRPC-Web protocol, in its binary or its base64 text framing.
+//
+// The request message is decoded into in, then call runs the method and sends its responses on the stream:
+// one for a unary method, any number for a server-streaming one. The status of the error call returns, if any,
+// is sent along with the trailer metadata in the trailer frame that ends the body.
+// The context of the stream expires after the grpc-timeout header if any, capped by the timeout of the method, and carries the allowed request
+// headers as grpc incoming metadata too, so that gRPC implementations read them as usual.
+func (o *ServerOptions) ServeGRPCWeb(w http.ResponseWriter, r *http.Request, in proto.Message, call func(stream *Stream) error) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var text bool
+	switch mediaType {
+	case "application/grpc-web", "application/grpc-web+proto":
+	case "application/grpc-web-text", "application/grpc-web-text+proto":
+		text = true
+	default:
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	ctx := o.NewContext(r)
+	if md, ok := FromIncomingContext(ctx); ok {
+		ctx = metadata.NewIncomingContext(ctx, metadata.MD(md.Copy()))
+	}
+	stream := &Stream{
+		ctx:         ctx,
+		w:           w,
+		text:        text,
+		contentType: mediaType,
+		header:      metadata.MD{},
+		trailer:     metadata.MD{},
+	}
+	if _, err := grpcTimeout(r.Header.Get("Grpc-Timeout")); err != nil {
+		stream.finish(err)
+		return
+	}
+	if err := o.decodeGRPCWeb(r, text, in); err != nil {
+		stream.finish(err)
+		return
+	}
+	o.Decoded(stream.ctx, in)
+	stream.finish(call(stream))
+}
+
+// decodeGRPCWeb reads the single message frame of a unary or server-streaming request into in.
+func (o *ServerOptions) decodeGRPCWeb(r *http.Request, text bool, in proto.Message) error {
+	o.limitBody(r)
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Errorf(CodeOf(err), "read request: %w", err)
+	}
+	if text {
+		data, err = decodeGRPCWebText(data)
+		if err != nil {
+			return Errorf(CodeInvalidArgument, "decode base64 request: %v", err)
+		}
+	}
+	if len(data) < 5 {
+		return NewError(CodeInvalidArgument, "request has no message frame")
+	}
+	flag, size := data[0], binary.BigEndian.Uint32(data[1:5])
+	if flag&grpcWebCompressed != 0 {
+		return NewError(CodeUnimplemented, "compressed messages are not supported")
+	}
+	if uint64(len(data)-5) < uint64(size) {
+		return NewError(CodeInvalidArgument, "request message frame is truncated")
+	}
+	if err := proto.Unmarshal(data[5:5+size], in); err != nil {
+		return Errorf(CodeInvalidArgument, "unmarshal request: %v", err)
+	}
+	return nil
+}
+
+// decodeGRPCWebText decodes a grpc-web-text body, which clients may send as several padded base64 chunks.
+func decodeGRPCWebText(data []byte) ([]byte, error) {
+	var out []byte
+	for len(data) > 0 {
+		n := bytes.IndexByte(data, '=')
+		if n < 0 {
+			n = len(data)
+		} else {
+			for n < len(data) && data[n] == '=' {
+				n++
+			}
+		}
+		chunk, err := base64.StdEncoding.DecodeString(string(data[:n]))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+		data = data[n:]
+	}
+	return out, nil
+}
+
+// grpcTimeout parses the value of a grpc-timeout header, e.g. "100m" for 100 milliseconds.
+func grpcTimeout(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[v[len(v)-1]]
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if !ok || err != nil || n <= 0 || len(v) > 9 {
+		return 0, Errorf(CodeInvalidArgument, "invalid grpc-timeout %q", v)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// Stream is the grpc.ServerStream of a method served with gRPC-Web.
+// Unary methods send their response with SendMsg, server-streaming ones get it wrapped in a ServerStream.
+type Stream struct {
+	ctx         context.Context
+	w           http.ResponseWriter
+	text        bool
+	contentType string
+
+	mu          sync.Mutex
+	header      metadata.MD
+	trailer     metadata.MD
+	wroteHeader bool
+}
+
+var _ grpc.ServerStream = (*Stream)(nil)
+
+// Context returns the context of the request.
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// SetHeader sets the header metadata, sent as HTTP headers along with the first message.
+func (s *Stream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wroteHeader {
+		return errors.New("runtime: header metadata already sent")
+	}
+	for k, vs := range md {
+		s.header.Append(k, vs...)
+	}
+	return nil
+}
+
+// SendHeader sets the header metadata and sends the HTTP headers at once.
+func (s *Stream) SendHeader(md metadata.MD) error {
+	if err := s.SetHeader(md); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeHeader()
+	return nil
+}
+
+// SetTrailer sets the trailer metadata, sent in the trailer frame that ends the body.
+func (s *Stream) SetTrailer(md metadata.MD) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, vs := range md {
+		s.trailer.Append(k, vs...)
+	}
+}
+
+// SendMsg sends m, which must be a proto.Message, in a message frame.
+func (s *Stream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("runtime: %T is not a proto.Message", m)
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return Errorf(CodeInternal, "marshal response: %v", err)
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeHeader()
+	return s.writeFrame(grpcWebDataFrame, data)
+}
+
+// RecvMsg reports io.EOF: the single request message of a gRPC-Web call is decoded before the method runs.
+func (s *Stream) RecvMsg(m any) error {
+	return io.EOF
+}
+
+// writeHeader writes the HTTP headers with the header metadata, once. s.mu must be held.
+func (s *Stream) writeHeader() {
+	if s.wroteHeader {
+		return
+	}
+	s.wroteHeader = true
+	h := s.w.Header()
+	if ss := serverStreamFromContext(s.ctx); ss != nil {
+		ss.mu.Lock()
+		for k, vs := range ss.header {
+			s.header.Append(k, vs...)
+		}
+		ss.mu.Unlock()
+	}
+	for k, vs := range s.header {
+		k = http.CanonicalHeaderKey(k)
+		h.Del(k)
+		for _, v := range vs {
+			h.Add(k, v)
+		}
+	}
+	h.Set("Content-Type", s.contentType)
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// writeFrame writes a frame with flag and data and flushes it to the client. s.mu must be held.
+func (s *Stream) writeFrame(flag byte, data []byte) error {
+	frame := make([]byte, 5+len(data))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+	copy(frame[5:], data)
+	if s.text {
+		frame = []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+	if _, err := s.w.Write(frame); err != nil {
+		return err
+	}
+	http.NewResponseController(s.w).Flush()
+	return nil
+}
+
+// finish ends the body with the trailer frame holding the status of err and the trailer metadata.
+func (s *Stream) finish(err error) {
+	setCallError(s.ctx, err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeHeader()
+
+	trailer := metadata.MD{}
+	if ss := serverStreamFromContext(s.ctx); ss != nil {
+		ss.mu.Lock()
+		for k, vs := range ss.trailer {
+			trailer.Append(k, vs...)
+		}
+		ss.mu.Unlock()
+	}
+	for k, vs := range s.trailer {
+		trailer.Append(k, vs...)
+	}
+	trailer.Set("grpc-status", strconv.Itoa(int(CodeOf(err))))
+	if err != nil {
+		trailer.Set("grpc-message", url.PathEscape(messageOf(err)))
+	}
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	for _, k := range keys {
+		for _, v := range trailer[k] {
+			b.WriteString(strings.ToLower(k) + ": " + v + "\r\n")
+		}
+	}
+	s.writeFrame(grpcWebTrailerFrame, b.Bytes())
+}
+
+// ServerStream is the grpc.ServerStreamingServer of a server-streaming method with Res responses served with gRPC-Web.
+type ServerStream[Res any] struct {
+	*Stream
+}
+
+var _ grpc.ServerStreamingServer[struct{}] = ServerStream[struct{}]{}
+
+// Send sends m in a message frame.
+func (s ServerStream[Res]) Send(m *Res) error {
+	return s.SendMsg(m)
+}
@@ -0,0 +1,163 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func grpcWebFrame(flag byte, data []byte) []byte {
+	frame := make([]byte, 5, 5+len(data))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+	return append(frame, data...)
+}
+
+// grpcWebFrames splits a binary gRPC-Web body into its frames.
+func grpcWebFrames(t *testing.T, body []byte) (messages []string, trailer string) {
+	t.Helper()
+	for len(body) > 0 {
+		if len(body) < 5 {
+			t.Fatalf("truncated frame %q", body)
+		}
+		size := binary.BigEndian.Uint32(body[1:5])
+		data := body[5 : 5+size]
+		if body[0] == grpcWebTrailerFrame {
+			trailer = string(data)
+		} else {
+			m := &wrapperspb.StringValue{}
+			if err := proto.Unmarshal(data, m); err != nil {
+				t.Fatalf("proto.Unmarshal() failed with %v", err)
+			}
+			messages = append(messages, m.Value)
+		}
+		body = body[5+size:]
+	}
+	return messages, trailer
+}
+
+func TestServeGRPCWeb(t *testing.T) {
+	req, _ := proto.Marshal(wrapperspb.String("hi"))
+	for _, spec := range []struct {
+		name        string
+		contentType string
+		call        func(in *wrapperspb.StringValue, stream *Stream) error
+		wantMsgs    []string
+		wantTrailer string
+		wantHeader  string
+	}{
+		{
+			name:        "unary",
+			contentType: "application/grpc-web+proto",
+			call: func(in *wrapperspb.StringValue, stream *Stream) error {
+				SetHeader(stream.Context(), Pairs("x-header", "h"))
+				stream.SetTrailer(metadata.Pairs("x-trailer", "t"))
+				return stream.SendMsg(in)
+			},
+			wantMsgs:    []string{"hi"},
+			wantTrailer: "grpc-status: 0\r\nx-trailer: t\r\n",
+			wantHeader:  "h",
+		},
+		{
+			name:        "text",
+			contentType: "application/grpc-web-text",
+			call: func(in *wrapperspb.StringValue, stream *Stream) error {
+				return stream.SendMsg(in)
+			},
+			wantMsgs:    []string{"hi"},
+			wantTrailer: "grpc-status: 0\r\n",
+		},
+		{
+			name:        "server streaming",
+			contentType: "application/grpc-web",
+			call: func(in *wrapperspb.StringValue, stream *Stream) error {
+				s := ServerStream[wrapperspb.StringValue]{Stream: stream}
+				for _, v := range []string{"a", "b", "c"} {
+					if err := s.Send(wrapperspb.String(v)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantMsgs:    []string{"a", "b", "c"},
+			wantTrailer: "grpc-status: 0\r\n",
+		},
+		{
+			name:        "grpc status",
+			contentType: "application/grpc-web",
+			call: func(in *wrapperspb.StringValue, stream *Stream) error {
+				md, _ := metadata.FromIncomingContext(stream.Context())
+				return status.Errorf(codes.PermissionDenied, "no access for %s", md.Get("authorization"))
+			},
+			wantTrailer: "grpc-message: no%20access%20for%20%5BBearer%20t%5D\r\ngrpc-status: 7\r\n",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			body := grpcWebFrame(grpcWebDataFrame, req)
+			if spec.contentType == "application/grpc-web-text" {
+				body = []byte(base64.StdEncoding.EncodeToString(body))
+			}
+			r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", bytes.NewReader(body))
+			r.Header.Set("Content-Type", spec.contentType)
+			r.Header.Set("Authorization", "Bearer t")
+			w := httptest.NewRecorder()
+			in := &wrapperspb.StringValue{}
+			NewServerOptions().ServeGRPCWeb(w, r, in, func(stream *Stream) error {
+				return spec.call(in, stream)
+			})
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d; want 200; body %q", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); got != spec.contentType {
+				t.Errorf("Content-Type = %q; want %q", got, spec.contentType)
+			}
+			if got := w.Header().Get("X-Header"); got != spec.wantHeader {
+				t.Errorf("X-Header = %q; want %q", got, spec.wantHeader)
+			}
+			rsp := w.Body.Bytes()
+			if spec.contentType == "application/grpc-web-text" {
+				var err error
+				if rsp, err = decodeGRPCWebText(rsp); err != nil {
+					t.Fatalf("decodeGRPCWebText() failed with %v", err)
+				}
+			}
+			msgs, trailer := grpcWebFrames(t, rsp)
+			if !reflect.DeepEqual(msgs, spec.wantMsgs) {
+				t.Errorf("messages = %q; want %q", msgs, spec.wantMsgs)
+			}
+			if trailer != spec.wantTrailer {
+				t.Errorf("trailer = %q; want %q", trailer, spec.wantTrailer)
+			}
+		})
+	}
+}
+
+func TestGRPCWebOr(t *testing.T) {
+	h := GRPCWebOr(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("grpc-web")) }),
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("other")) }),
+	)
+	for contentType, want := range map[string]string{
+		"application/grpc-web-text+proto": "grpc-web",
+		"application/json":                "other",
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", nil)
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got := w.Body.String(); got != want {
+			t.Errorf("GRPCWebOr() served %s with %q; want %q", contentType, got, want)
+		}
+	}
+}
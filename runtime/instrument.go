@@ -0,0 +1,156 @@
+package runtime
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Instrument returns h instrumented as the handler of the method fullMethod, e.g. "/library.v1.Library/GetBook",
+// registered with the ServeMux path route, e.g. "/v1/books/{id}". It traces the calls when WithTracing is given
+// and reports them to the handlers given with WithStatsHandler. It returns h unchanged when there is nothing to do.
+// Generated handlers wrap themselves with it.
+func (o *ServerOptions) Instrument(fullMethod, route string, h http.Handler) http.Handler {
+	if o.tracerProvider == nil && len(o.statsHandlers) == 0 {
+		return h
+	}
+	service, method := splitFullMethod(fullMethod)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		begin := time.Now()
+		c := &call{
+			info: &CallInfo{
+				FullMethod: fullMethod,
+				Service:    service,
+				Method:     method,
+				Route:      route,
+				HTTPMethod: r.Method,
+			},
+			statsHandlers: o.statsHandlers,
+		}
+		rec := &statusRecorder{ResponseWriter: w}
+		body := &countingReader{ReadCloser: r.Body}
+		ctx := context.WithValue(r.Context(), callKey{}, c)
+		endSpan := func(int, error) {}
+		if o.tracerProvider != nil {
+			ctx, endSpan = o.startSpan(ctx, r, c)
+		}
+		for _, sh := range o.statsHandlers {
+			ctx = sh.Begin(ctx, c.info)
+		}
+		r = r.WithContext(ctx)
+		r.Body = body
+		h.ServeHTTP(rec, r)
+
+		err := c.error()
+		stats := &CallStats{
+			Status:        rec.status(),
+			Code:          CodeOf(err),
+			Err:           err,
+			RequestBytes:  body.n,
+			ResponseBytes: rec.n,
+			Duration:      time.Since(begin),
+		}
+		for _, sh := range o.statsHandlers {
+			sh.End(ctx, c.info, stats)
+		}
+		endSpan(stats.Status, err)
+	})
+}
+
+// Decoded reports to the stats handlers that req, the request of the call of ctx, is decoded and that the
+// implementation runs next. Generated handlers call it.
+func (o *ServerOptions) Decoded(ctx context.Context, req proto.Message) {
+	c, ok := ctx.Value(callKey{}).(*call)
+	if !ok {
+		return
+	}
+	for _, sh := range c.statsHandlers {
+		sh.Decoded(ctx, c.info, req)
+	}
+}
+
+// call is the state of a call instrumented by Instrument, shared with the functions writing its response.
+type call struct {
+	info          *CallInfo
+	statsHandlers []StatsHandler
+
+	mu  sync.Mutex
+	err error
+}
+
+type callKey struct{}
+
+// setCallError records err as the error the call of ctx answered with, if ctx is instrumented.
+func setCallError(ctx context.Context, err error) {
+	c, ok := ctx.Value(callKey{}).(*call)
+	if !ok || err == nil {
+		return
+	}
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+}
+
+func (c *call) error() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// splitFullMethod splits "/package.Service/Method" into "package.Service" and "Method".
+func splitFullMethod(fullMethod string) (service, method string) {
+	service, method, _ = strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service, method
+}
+
+// statusRecorder is a http.ResponseWriter recording the status and the number of bytes written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+	n    int64
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController to flush gRPC-Web frames.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusRecorder) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+// countingReader is a request body counting the bytes read from it.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,197 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// MD is a mapping from metadata keys to values, in the spirit of grpc's metadata.MD.
+// Keys are always lower case.
+type MD map[string][]string
+
+// Pairs returns an MD formed by the mapping of key, value pairs.
+// Pairs panics if len(kv) is odd.
+func Pairs(kv ...string) MD {
+	if len(kv)%2 == 1 {
+		panic("runtime: Pairs got an odd number of input pairs")
+	}
+	md := MD{}
+	for i := 0; i < len(kv); i += 2 {
+		md.Append(kv[i], kv[i+1])
+	}
+	return md
+}
+
+// Get obtains the values for a given key.
+func (md MD) Get(k string) []string {
+	return md[strings.ToLower(k)]
+}
+
+// Set sets the value of a given key with a slice of values.
+func (md MD) Set(k string, vals ...string) {
+	if len(vals) == 0 {
+		return
+	}
+	md[strings.ToLower(k)] = vals
+}
+
+// Append adds the values to key k, not overwriting what was already stored at that key.
+func (md MD) Append(k string, vals ...string) {
+	if len(vals) == 0 {
+		return
+	}
+	k = strings.ToLower(k)
+	md[k] = append(md[k], vals...)
+}
+
+// Copy returns a copy of md.
+func (md MD) Copy() MD {
+	out := make(MD, len(md))
+	for k, v := range md {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+type incomingKey struct{}
+
+// NewIncomingContext creates a new context with incoming md attached.
+func NewIncomingContext(ctx context.Context, md MD) context.Context {
+	return context.WithValue(ctx, incomingKey{}, md)
+}
+
+// FromIncomingContext returns the incoming metadata in ctx if it exists.
+// Generated handlers populate it from the request headers allowed by WithIncomingHeaders.
+func FromIncomingContext(ctx context.Context) (MD, bool) {
+	md, ok := ctx.Value(incomingKey{}).(MD)
+	if !ok {
+		return nil, false
+	}
+	return md.Copy(), true
+}
+
+// serverStream collects the response metadata set by an implementation while it serves one request.
+type serverStream struct {
+	mu      sync.Mutex
+	header  MD
+	trailer MD
+	status  int
+}
+
+type serverStreamKey struct{}
+
+func newServerStreamContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, serverStreamKey{}, &serverStream{header: MD{}, trailer: MD{}})
+}
+
+func serverStreamFromContext(ctx context.Context) *serverStream {
+	ss, _ := ctx.Value(serverStreamKey{}).(*serverStream)
+	return ss
+}
+
+var errNoServerStream = errors.New("runtime: context is not served by a generated handler")
+
+// SetHeader sets the header metadata to be sent as HTTP response headers.
+// When called multiple times, all the provided metadata will be merged.
+func SetHeader(ctx context.Context, md MD) error {
+	ss := serverStreamFromContext(ctx)
+	if ss == nil {
+		return errNoServerStream
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for k, v := range md {
+		ss.header.Append(k, v...)
+	}
+	return nil
+}
+
+// SetTrailer sets the trailer metadata to be sent as HTTP response trailers after the body.
+// When called multiple times, all the provided metadata will be merged.
+func SetTrailer(ctx context.Context, md MD) error {
+	ss := serverStreamFromContext(ctx)
+	if ss == nil {
+		return errNoServerStream
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for k, v := range md {
+		ss.trailer.Append(k, v...)
+	}
+	return nil
+}
+
+// SetStatus overrides the HTTP status code written when the method succeeds,
+// e.g. to answer 201 or 204 where the method declares 200. The code must be a 2xx or 3xx status:
+// failures are reported by returning an error, and informational statuses are no final response.
+func SetStatus(ctx context.Context, code int) error {
+	ss := serverStreamFromContext(ctx)
+	if ss == nil {
+		return errNoServerStream
+	}
+	if code < 200 || code > 399 {
+		return fmt.Errorf("runtime: status code %d is not a success or redirection status", code)
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.status = code
+	return nil
+}
+
+// statusFromContext returns the status code given to SetStatus, or def if there is none.
+func statusFromContext(ctx context.Context, def int) int {
+	ss := serverStreamFromContext(ctx)
+	if ss == nil {
+		return def
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.status == 0 {
+		return def
+	}
+	return ss.status
+}
+
+// writeHeader copies the header metadata in ctx into w and declares the trailers to come.
+// It must be called before w.WriteHeader.
+func writeHeader(ctx context.Context, w http.ResponseWriter) {
+	ss := serverStreamFromContext(ctx)
+	if ss == nil {
+		return
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	h := w.Header()
+	for k, vs := range ss.header {
+		k = http.CanonicalHeaderKey(k)
+		h.Del(k)
+		for _, v := range vs {
+			h.Add(k, v)
+		}
+	}
+	for k := range ss.trailer {
+		h.Add("Trailer", http.CanonicalHeaderKey(k))
+	}
+}
+
+// writeTrailer copies the trailer metadata in ctx into w.
+// It must be called after the body has been written.
+func writeTrailer(ctx context.Context, w http.ResponseWriter) {
+	ss := serverStreamFromContext(ctx)
+	if ss == nil {
+		return
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	h := w.Header()
+	for k, vs := range ss.trailer {
+		k = http.CanonicalHeaderKey(k)
+		for _, v := range vs {
+			h.Add(k, v)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestIncomingHeaders(t *testing.T) {
+	for _, spec := range []struct {
+		allow []string
+		want  MD
+	}{
+		{
+			allow: nil,
+			want:  MD{"authorization": {"Bearer t"}, "x-request-id": {"1"}},
+		},
+		{
+			allow: []string{"X-Custom-*"},
+			want:  MD{"x-custom-a": {"a"}, "x-custom-b": {"b1", "b2"}},
+		},
+		{
+			allow: []string{},
+			want:  MD{},
+		},
+	} {
+		var opts []ServerOption
+		if spec.allow != nil {
+			opts = append(opts, WithIncomingHeaders(spec.allow...))
+		}
+		o := NewServerOptions(opts...)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer t")
+		r.Header.Set("X-Request-Id", "1")
+		r.Header.Set("X-Custom-A", "a")
+		r.Header.Add("X-Custom-B", "b1")
+		r.Header.Add("X-Custom-B", "b2")
+		r.Header.Set("Cookie", "secret")
+
+		md, ok := FromIncomingContext(o.NewContext(r))
+		if !ok {
+			t.Fatalf("FromIncomingContext() found no metadata; allow=%q", spec.allow)
+		}
+		if got, want := md, spec.want; !reflect.DeepEqual(got, want) {
+			t.Errorf("FromIncomingContext() = %v; want %v; allow=%q", got, want, spec.allow)
+		}
+	}
+}
+
+func TestSetHeaderAndTrailer(t *testing.T) {
+	o := NewServerOptions()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := o.NewContext(r)
+
+	if err := SetHeader(ctx, Pairs("x-a", "1")); err != nil {
+		t.Fatalf("SetHeader() failed with %v", err)
+	}
+	if err := SetHeader(ctx, Pairs("x-a", "2", "Location", "/v1/things/1")); err != nil {
+		t.Fatalf("SetHeader() failed with %v", err)
+	}
+	if err := SetTrailer(ctx, Pairs("x-checksum", "abc")); err != nil {
+		t.Fatalf("SetTrailer() failed with %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	o.WriteResponse(ctx, rec, http.StatusOK, map[string]string{"id": "1"})
+	rsp := rec.Result()
+
+	if got, want := rsp.Header.Values("X-A"), []string{"1", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("header X-A = %q; want %q", got, want)
+	}
+	if got, want := rsp.Header.Get("Location"), "/v1/things/1"; got != want {
+		t.Errorf("header Location = %q; want %q", got, want)
+	}
+	if got, want := rsp.Trailer.Get("X-Checksum"), "abc"; got != want {
+		t.Errorf("trailer X-Checksum = %q; want %q", got, want)
+	}
+
+	if err := SetHeader(r.Context(), Pairs("x-a", "1")); err == nil {
+		t.Errorf("SetHeader() outside a generated handler succeeded; want error")
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	for _, spec := range []struct {
+		declared int
+		set      int
+		want     int
+		wantBody bool
+	}{
+		{declared: http.StatusOK, want: http.StatusOK, wantBody: true},
+		{declared: http.StatusCreated, want: http.StatusCreated, wantBody: true},
+		{declared: http.StatusNoContent, want: http.StatusNoContent},
+		{declared: http.StatusOK, set: http.StatusAccepted, want: http.StatusAccepted, wantBody: true},
+		{declared: http.StatusOK, set: http.StatusNoContent, want: http.StatusNoContent},
+	} {
+		o := NewServerOptions()
+		ctx := o.NewContext(httptest.NewRequest(http.MethodPost, "/", nil))
+		if spec.set != 0 {
+			if err := SetStatus(ctx, spec.set); err != nil {
+				t.Fatalf("SetStatus(%d) failed with %v", spec.set, err)
+			}
+		}
+		rec := httptest.NewRecorder()
+		o.WriteResponse(ctx, rec, spec.declared, map[string]string{"id": "1"})
+		if got, want := rec.Code, spec.want; got != want {
+			t.Errorf("WriteResponse(%d) with SetStatus(%d) wrote status %d; want %d", spec.declared, spec.set, got, want)
+		}
+		if got, want := rec.Body.Len() > 0, spec.wantBody; got != want {
+			t.Errorf("WriteResponse(%d) with SetStatus(%d) wrote body %q; want body %v", spec.declared, spec.set, rec.Body, want)
+		}
+	}
+}
+
+func TestSetStatusInvalid(t *testing.T) {
+	o := NewServerOptions()
+	ctx := o.NewContext(httptest.NewRequest(http.MethodPost, "/", nil))
+	for _, code := range []int{0, http.StatusContinue, http.StatusNotFound, http.StatusInternalServerError, 600} {
+		if err := SetStatus(ctx, code); err == nil {
+			t.Errorf("SetStatus(%d) succeeded; want error", code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	o.WriteResponse(ctx, rec, http.StatusOK, map[string]string{"id": "1"})
+	if rec.Code != http.StatusOK {
+		t.Errorf("WriteResponse() after invalid SetStatus calls wrote status %d; want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,183 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the latency histogram of NewMetrics when none are given,
+// the ones of the Prometheus client libraries.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics is a StatsHandler counting the calls, their latency and payload sizes per method.
+// It is also a http.Handler serving them in the Prometheus text exposition format, to be registered on the
+// ServeMux of the service, e.g. at "GET /metrics", without a separate metrics server or client library:
+//
+//	metrics := runtime.NewMetrics()
+//	mux.Handle("GET /metrics", metrics)
+//	err := RegisterHttpServer(mux, impl, runtime.WithStatsHandler(metrics))
+type Metrics struct {
+	buckets []float64
+
+	mu      sync.Mutex
+	methods map[methodKey]*methodMetrics
+}
+
+type methodKey struct {
+	service, method string
+}
+
+type handledKey struct {
+	code   Code
+	status int
+}
+
+type methodMetrics struct {
+	started       uint64
+	handled       map[handledKey]uint64
+	buckets       []uint64
+	sum           float64
+	count         uint64
+	requestBytes  uint64
+	responseBytes uint64
+}
+
+var _ StatsHandler = (*Metrics)(nil)
+
+// NewMetrics returns Metrics whose latency histogram has the given bucket upper bounds in seconds,
+// DefaultBuckets when none are given.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{buckets: buckets, methods: map[methodKey]*methodMetrics{}}
+}
+
+// method returns the metrics of the method of info. m.mu must be held.
+func (m *Metrics) method(info *CallInfo) *methodMetrics {
+	k := methodKey{info.Service, info.Method}
+	mm, ok := m.methods[k]
+	if !ok {
+		mm = &methodMetrics{handled: map[handledKey]uint64{}, buckets: make([]uint64, len(m.buckets))}
+		m.methods[k] = mm
+	}
+	return mm
+}
+
+// Begin counts the call as started.
+func (m *Metrics) Begin(ctx context.Context, info *CallInfo) context.Context {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.method(info).started++
+	return ctx
+}
+
+// Decoded does nothing.
+func (m *Metrics) Decoded(ctx context.Context, info *CallInfo, req proto.Message) {}
+
+// End counts the call as handled with its code and status, and records its latency and payload sizes.
+func (m *Metrics) End(ctx context.Context, info *CallInfo, stats *CallStats) {
+	seconds := stats.Duration.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm := m.method(info)
+	mm.handled[handledKey{stats.Code, stats.Status}]++
+	for i, le := range m.buckets {
+		if seconds <= le {
+			mm.buckets[i]++
+		}
+	}
+	mm.sum += seconds
+	mm.count++
+	mm.requestBytes += uint64(stats.RequestBytes)
+	mm.responseBytes += uint64(stats.ResponseBytes)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]methodKey, 0, len(m.methods))
+	for k := range m.methods {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].service != keys[j].service {
+			return keys[i].service < keys[j].service
+		}
+		return keys[i].method < keys[j].method
+	})
+	labels := func(k methodKey, extra ...string) string {
+		pairs := append([]string{"rpc_service", k.service, "rpc_method", k.method}, extra...)
+		var b strings.Builder
+		for i := 0; i < len(pairs); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(pairs[i] + `="` + escapeLabel(pairs[i+1]) + `"`)
+		}
+		return "{" + b.String() + "}"
+	}
+
+	header := func(name, typ, help string) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	header("http_go_server_started_total", "counter", "Calls started by the generated handlers.")
+	for _, k := range keys {
+		fmt.Fprintf(bw, "http_go_server_started_total%s %d\n", labels(k), m.methods[k].started)
+	}
+	header("http_go_server_handled_total", "counter", "Calls completed by the generated handlers, by code and HTTP status.")
+	for _, k := range keys {
+		mm := m.methods[k]
+		handled := make([]handledKey, 0, len(mm.handled))
+		for hk := range mm.handled {
+			handled = append(handled, hk)
+		}
+		sort.Slice(handled, func(i, j int) bool {
+			if handled[i].code != handled[j].code {
+				return handled[i].code < handled[j].code
+			}
+			return handled[i].status < handled[j].status
+		})
+		for _, hk := range handled {
+			fmt.Fprintf(bw, "http_go_server_handled_total%s %d\n", labels(k, "code", hk.code.String(), "http_status", strconv.Itoa(hk.status)), mm.handled[hk])
+		}
+	}
+	header("http_go_server_handling_seconds", "histogram", "Latency of the calls completed by the generated handlers.")
+	for _, k := range keys {
+		mm := m.methods[k]
+		for i, le := range m.buckets {
+			fmt.Fprintf(bw, "http_go_server_handling_seconds_bucket%s %d\n", labels(k, "le", strconv.FormatFloat(le, 'g', -1, 64)), mm.buckets[i])
+		}
+		fmt.Fprintf(bw, "http_go_server_handling_seconds_bucket%s %d\n", labels(k, "le", "+Inf"), mm.count)
+		fmt.Fprintf(bw, "http_go_server_handling_seconds_sum%s %s\n", labels(k), strconv.FormatFloat(mm.sum, 'g', -1, 64))
+		fmt.Fprintf(bw, "http_go_server_handling_seconds_count%s %d\n", labels(k), mm.count)
+	}
+	header("http_go_server_request_bytes_total", "counter", "Bytes read from the request bodies of the calls.")
+	for _, k := range keys {
+		fmt.Fprintf(bw, "http_go_server_request_bytes_total%s %d\n", labels(k), m.methods[k].requestBytes)
+	}
+	header("http_go_server_response_bytes_total", "counter", "Bytes written in the response bodies of the calls.")
+	for _, k := range keys {
+		fmt.Fprintf(bw, "http_go_server_response_bytes_total%s %d\n", labels(k), m.methods[k].responseBytes)
+	}
+}
+
+// escapeLabel escapes a label value of the Prometheus text format.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
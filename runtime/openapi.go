@@ -0,0 +1,41 @@
+package runtime
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+//go:embed explorer.html
+var explorerHTML string
+
+// HandleOpenAPI registers on mux the OpenAPI document spec and the explorer page, at the paths given by WithOpenAPI and WithExplorer.
+// Nothing is registered for a path that was not configured, or when spec is empty.
+func (o *ServerOptions) HandleOpenAPI(mux interface{ Handle(string, http.Handler) }, spec []byte) {
+	if o.openAPIPath == "" || len(spec) == 0 {
+		return
+	}
+	mux.Handle(o.CORS("GET "+o.openAPIPath, OpenAPIHandler(spec)))
+	if o.explorerPath != "" {
+		mux.Handle("GET "+o.explorerPath, ExplorerHandler(o.openAPIPath))
+	}
+}
+
+// OpenAPIHandler returns a handler serving the OpenAPI document spec.
+func OpenAPIHandler(spec []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	})
+}
+
+// ExplorerHandler returns a handler serving the explorer page for the OpenAPI document at specURL.
+func ExplorerHandler(specURL string) http.Handler {
+	u, _ := json.Marshal(specURL)
+	page := strings.Replace(explorerHTML, "/*SPEC_URL*/\"\"", string(u), 1)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	})
+}
@@ -0,0 +1,39 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleOpenAPI(t *testing.T) {
+	spec := []byte(`{"openapi":"3.0.3"}`)
+
+	mux := http.NewServeMux()
+	NewServerOptions(WithOpenAPI("/docs/openapi.json"), WithExplorer("/docs/")).HandleOpenAPI(mux, spec)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	if got, want := rec.Body.String(), string(spec); got != want {
+		t.Errorf("GET /docs/openapi.json = %q; want %q", got, want)
+	}
+	if got, want := rec.Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("GET /docs/openapi.json Content-Type = %q; want %q", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	if got, want := rec.Body.String(), `var specURL = "/docs/openapi.json";`; !strings.Contains(got, want) {
+		t.Errorf("GET /docs/ does not contain %q", want)
+	}
+
+	// Without WithOpenAPI nothing is served.
+	mux = http.NewServeMux()
+	NewServerOptions(WithExplorer("/docs/")).HandleOpenAPI(mux, spec)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	if got, want := rec.Code, http.StatusNotFound; got != want {
+		t.Errorf("GET /docs/ without WithOpenAPI = %d; want %d", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The backoff of WaitOperation: the first poll happens after operationPollDelay, and each following one
+// operationPollFactor times later than the previous one, at most operationPollMaxDelay later.
+var (
+	operationPollDelay    = 200 * time.Millisecond
+	operationPollFactor   = 1.5
+	operationPollMaxDelay = 10 * time.Second
+)
+
+// SetOperationLocation sets the Location header of the response of a long-running method, see AIP-151,
+// to the route of the generated XxxOperationsHandler getting the operation with the name, which follows
+// the /operations/ prefix verbatim: "projects/p/operations/1" is at /operations/projects/p/operations/1.
+func (o *ServerOptions) SetOperationLocation(w http.ResponseWriter, name string) {
+	if name == "" {
+		return
+	}
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	w.Header().Set("Location", "/operations/"+strings.Join(segments, "/"))
+}
+
+// HandleOperations registers on mux hdr, the handler of the operations route pattern returned by a generated
+// XxxOperationsHandler, unless mux already routes pattern: the services with long-running methods share the route,
+// and the first one registered on mux serves the operations of all. A mux without the Handler method of
+// http.ServeMux gets hdr registered every time.
+func (o *ServerOptions) HandleOperations(mux interface{ Handle(string, http.Handler) }, pattern string, hdr http.Handler) {
+	if m, ok := mux.(interface {
+		Handler(*http.Request) (http.Handler, string)
+	}); ok {
+		method, path, _ := strings.Cut(pattern, " ")
+		r := &http.Request{Method: method, URL: &url.URL{Path: strings.TrimSuffix(path, "{name...}") + "_"}}
+		if _, registered := m.Handler(r); registered == pattern {
+			return
+		}
+	}
+	mux.Handle(o.CORS(pattern, hdr))
+}
+
+// WaitOperation polls op, a google.longrunning.Operation, with get until it is done, waiting longer between each
+// poll, and then unmarshals its response into resp. It returns the error of the operation as an *Error with its
+// code, the error of get, or the error of ctx when it expires first.
+func WaitOperation[Op proto.Message](ctx context.Context, op Op, get func(ctx context.Context, name string) (Op, error), resp proto.Message) error {
+	delay := operationPollDelay
+	for {
+		m := op.ProtoReflect()
+		fields := m.Descriptor().Fields()
+		if m.Get(fields.ByName("done")).Bool() {
+			return operationResult(m, resp)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(time.Duration(float64(delay)*operationPollFactor), operationPollMaxDelay)
+
+		var err error
+		if op, err = get(ctx, m.Get(fields.ByName("name")).String()); err != nil {
+			return err
+		}
+	}
+}
+
+// operationResult returns the error of the done operation m, or unmarshals its google.protobuf.Any response into resp.
+func operationResult(m protoreflect.Message, resp proto.Message) error {
+	fields := m.Descriptor().Fields()
+	if fd := fields.ByName("error"); m.Has(fd) {
+		st := m.Get(fd).Message()
+		stFields := st.Descriptor().Fields()
+		return NewError(Code(st.Get(stFields.ByName("code")).Int()), st.Get(stFields.ByName("message")).String())
+	}
+	fd := fields.ByName("response")
+	if !m.Has(fd) {
+		return nil
+	}
+	anyMsg := m.Get(fd).Message()
+	anyFields := anyMsg.Descriptor().Fields()
+	typeURL := anyMsg.Get(anyFields.ByName("type_url")).String()
+	if name := resp.ProtoReflect().Descriptor().FullName(); typeURL[strings.LastIndex(typeURL, "/")+1:] != string(name) {
+		return Errorf(CodeInternal, "operation response is a %s, not a %s", typeURL, name)
+	}
+	if err := proto.Unmarshal(anyMsg.Get(anyFields.ByName("value")).Bytes(), resp); err != nil {
+		return Errorf(CodeInternal, "unmarshal operation response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// operationDescriptor returns the descriptor of a google.longrunning.Operation.
+func operationDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	return testMessages(t, `
+syntax: "proto3"
+name: "google/longrunning/operations.proto"
+package: "google.longrunning"
+dependency: "google/protobuf/any.proto"
+dependency: "google/rpc/status.proto"
+message_type: {
+	name: "Operation"
+	field: { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+	field: { name: "done" number: 3 type: TYPE_BOOL label: LABEL_OPTIONAL json_name: "done" }
+	field: { name: "error" number: 4 type: TYPE_MESSAGE type_name: ".google.rpc.Status" label: LABEL_OPTIONAL oneof_index: 0 json_name: "error" }
+	field: { name: "response" number: 5 type: TYPE_MESSAGE type_name: ".google.protobuf.Any" label: LABEL_OPTIONAL oneof_index: 0 json_name: "response" }
+	oneof_decl: { name: "result" }
+}
+`).ByName("Operation")
+}
+
+func TestWaitOperation(t *testing.T) {
+	defer func(delay time.Duration) { operationPollDelay = delay }(operationPollDelay)
+	operationPollDelay = time.Millisecond
+
+	desc := operationDescriptor(t)
+	newOperation := func(text string) *dynamicpb.Message { return newTestMessage(t, desc, text) }
+	response, err := anypb.New(&descriptorpb.FileDescriptorProto{Name: proto.String("library.proto")})
+	if err != nil {
+		t.Fatalf("anypb.New() failed with %v", err)
+	}
+	done := newOperation(`name: "operations/1" done: true`)
+	done.Set(desc.Fields().ByName("response"), protoreflect.ValueOfMessage(response.ProtoReflect()))
+
+	for _, spec := range []struct {
+		name     string
+		ops      []*dynamicpb.Message
+		resp     proto.Message
+		want     string
+		wantCode Code
+	}{
+		{
+			name: "response",
+			ops:  []*dynamicpb.Message{newOperation(`name: "operations/1"`), newOperation(`name: "operations/1"`), done},
+			resp: &descriptorpb.FileDescriptorProto{},
+			want: "library.proto",
+		},
+		{
+			name:     "error",
+			ops:      []*dynamicpb.Message{newOperation(`name: "operations/1" done: true error: { code: 5 message: "shelf not found" }`)},
+			resp:     &descriptorpb.FileDescriptorProto{},
+			wantCode: CodeNotFound,
+		},
+		{
+			name:     "unexpected response",
+			ops:      []*dynamicpb.Message{done},
+			resp:     &descriptorpb.DescriptorProto{},
+			wantCode: CodeInternal,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			polls := 0
+			get := func(ctx context.Context, name string) (*dynamicpb.Message, error) {
+				if name != "operations/1" {
+					t.Errorf("get(%q), want the name of the operation", name)
+				}
+				polls++
+				return spec.ops[polls], nil
+			}
+			err := WaitOperation(context.Background(), spec.ops[0], get, spec.resp)
+			if CodeOf(err) != spec.wantCode {
+				t.Fatalf("WaitOperation() failed with %v, want code %v", err, spec.wantCode)
+			}
+			if polls != len(spec.ops)-1 {
+				t.Errorf("WaitOperation() polled %d times, want %d", polls, len(spec.ops)-1)
+			}
+			if got := spec.resp.(interface{ GetName() string }).GetName(); got != spec.want {
+				t.Errorf("WaitOperation() unmarshaled the name %q, want %q", got, spec.want)
+			}
+		})
+	}
+
+	// Waiting stops when ctx expires.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	pending := newOperation(`name: "operations/2"`)
+	get := func(ctx context.Context, name string) (*dynamicpb.Message, error) { return pending, nil }
+	if err := WaitOperation(ctx, pending, get, &descriptorpb.FileDescriptorProto{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitOperation() failed with %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestSetOperationLocation(t *testing.T) {
+	o := NewServerOptions()
+	for name, want := range map[string]string{
+		"operations/export-1":     "/operations/operations/export-1",
+		"projects/p/operations/1": "/operations/projects/p/operations/1",
+		"operations/a b":          "/operations/operations/a%20b",
+		"":                        "",
+	} {
+		w := httptest.NewRecorder()
+		o.SetOperationLocation(w, name)
+		if got := w.Header().Get("Location"); got != want {
+			t.Errorf("SetOperationLocation(%q) set Location to %q, want %q", name, got, want)
+		}
+		if want == "" {
+			continue
+		}
+		// The name is found back by the route of the generated XxxOperationsHandler.
+		var got string
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /operations/{name...}", func(w http.ResponseWriter, r *http.Request) { got = r.PathValue("name") })
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, w.Header().Get("Location"), nil))
+		if got != name {
+			t.Errorf("the Location of %q routes to the operation %q", name, got)
+		}
+	}
+}
+
+func TestHandleOperations(t *testing.T) {
+	const pattern = "GET /operations/{name...}"
+	o := NewServerOptions()
+	mux := http.NewServeMux()
+	for _, service := range []string{"Books", "Shelves"} {
+		// The second registration is skipped, registering the pattern twice would panic.
+		o.HandleOperations(mux, pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Service", service)
+			_, _ = w.Write([]byte(r.PathValue("name")))
+		}))
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/operations/projects/p/operations/1", nil))
+	if got, want := w.Header().Get("Service"), "Books"; got != want {
+		t.Errorf("the operations route is served by %q, want %q", got, want)
+	}
+	if got, want := w.Body.String(), "projects/p/operations/1"; got != want {
+		t.Errorf("the operations route got the name %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"context"
+	"iter"
+	"net/http"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithMaxPageSize caps the page_size of the requests of the AIP-158 paginated methods at n: larger page sizes
+// are lowered to n, as AIP-158 asks. A value <= 0, the default, means no cap.
+func WithMaxPageSize(n int32) ServerOption {
+	return func(o *ServerOptions) {
+		o.maxPageSize = n
+	}
+}
+
+// PageSize returns the page size served for the page_size n of a request of a paginated method,
+// capped by WithMaxPageSize. Negative page sizes fail with invalid_argument.
+func (o *ServerOptions) PageSize(n int32) (int32, error) {
+	if n < 0 {
+		return 0, Errorf(CodeInvalidArgument, "page_size %d is negative", n)
+	}
+	if o.maxPageSize > 0 && n > o.maxPageSize {
+		return o.maxPageSize, nil
+	}
+	return n, nil
+}
+
+// SetNextPage sets the Link header of the response to a GET request r of a paginated method to the URL of the
+// next page, r's with the page_token query parameter set to token. It does nothing for the last page,
+// whose token is empty.
+func (o *ServerOptions) SetNextPage(w http.ResponseWriter, r *http.Request, token string) {
+	if token == "" || r.Method != http.MethodGet {
+		return
+	}
+	u := *r.URL
+	query := u.Query()
+	query.Set("page_token", token)
+	u.RawQuery = query.Encode()
+	w.Header().Add("Link", "<"+u.RequestURI()+`>; rel="next"`)
+}
+
+// Paginate returns an iterator over the items of the pages of an AIP-158 paginated method, starting with the page
+// of req. list calls the method, e.g. through a client, and items returns the items of a page.
+// The following pages are listed with a copy of req whose page_token is the next_page_token of the previous page,
+// until it is empty. An error of list is yielded last.
+func Paginate[Req proto.Message, Resp interface{ GetNextPageToken() string }, Item any](ctx context.Context, req Req, list func(context.Context, Req) (Resp, error), items func(Resp) []Item) iter.Seq2[Item, error] {
+	return func(yield func(Item, error) bool) {
+		req := proto.Clone(req).(Req)
+		m := req.ProtoReflect()
+		pageToken := m.Descriptor().Fields().ByName("page_token")
+		for {
+			resp, err := list(ctx, req)
+			if err != nil {
+				var zero Item
+				yield(zero, err)
+				return
+			}
+			for _, item := range items(resp) {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			token := resp.GetNextPageToken()
+			if token == "" || pageToken == nil {
+				return
+			}
+			m.Set(pageToken, protoreflect.ValueOfString(token))
+		}
+	}
+}
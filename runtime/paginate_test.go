@@ -0,0 +1,130 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// listRequest returns an empty AIP-158 list request.
+func listRequest(t *testing.T) *dynamicpb.Message {
+	t.Helper()
+	return dynamicpb.NewMessage(testMessages(t, `
+syntax: "proto3"
+name: "library/v1/list.proto"
+package: "library.v1"
+message_type: {
+	name: "ListBooksRequest"
+	field: { name: "page_size" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "pageSize" }
+	field: { name: "page_token" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "pageToken" }
+}
+`).ByName("ListBooksRequest"))
+}
+
+type bookPage struct {
+	books []string
+	next  string
+}
+
+func (p *bookPage) GetNextPageToken() string { return p.next }
+
+func TestPaginate(t *testing.T) {
+	pages := map[string]*bookPage{
+		"":   {books: []string{"Dune", "Emma"}, next: "p2"},
+		"p2": {books: []string{"Ulysses"}, next: "p3"},
+		"p3": {books: []string{"Walden"}},
+	}
+	req := listRequest(t)
+	pageToken := req.Descriptor().Fields().ByName("page_token")
+	var tokens []string
+	list := func(ctx context.Context, req *dynamicpb.Message) (*bookPage, error) {
+		token := req.Get(pageToken).String()
+		tokens = append(tokens, token)
+		if page, ok := pages[token]; ok {
+			return page, nil
+		}
+		return nil, NewError(CodeInvalidArgument, "bad token")
+	}
+	items := func(p *bookPage) []string { return p.books }
+
+	var got []string
+	for book, err := range Paginate(context.Background(), req, list, items) {
+		if err != nil {
+			t.Fatalf("Paginate() failed with %v", err)
+		}
+		got = append(got, book)
+	}
+	if want := []string{"Dune", "Emma", "Ulysses", "Walden"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Paginate() = %q, want %q", got, want)
+	}
+	if want := []string{"", "p2", "p3"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("Paginate() listed the pages %q, want %q", tokens, want)
+	}
+	if req.Has(pageToken) {
+		t.Errorf("Paginate() modified the request")
+	}
+
+	// Breaking out of the loop stops listing, an error of list is yielded.
+	tokens = nil
+	for range Paginate(context.Background(), req, list, items) {
+		break
+	}
+	if len(tokens) != 1 {
+		t.Errorf("Paginate() listed %d pages after a break, want 1", len(tokens))
+	}
+	pages[""].next = "bad"
+	var errs []error
+	for _, err := range Paginate(context.Background(), req, list, items) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var e *Error
+	if len(errs) != 1 || !errors.As(errs[0], &e) || e.Code() != CodeInvalidArgument {
+		t.Errorf("Paginate() yielded the errors %v, want the error of list", errs)
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	for _, spec := range []struct {
+		opts     []ServerOption
+		size     int32
+		want     int32
+		wantCode Code
+	}{
+		{size: 1000, want: 1000},
+		{opts: []ServerOption{WithMaxPageSize(100)}, size: 1000, want: 100},
+		{opts: []ServerOption{WithMaxPageSize(100)}, size: 10, want: 10},
+		{opts: []ServerOption{WithMaxPageSize(100)}, size: 0, want: 0},
+		{opts: []ServerOption{WithMaxPageSize(100)}, size: -1, wantCode: CodeInvalidArgument},
+	} {
+		got, err := NewServerOptions(spec.opts...).PageSize(spec.size)
+		if got != spec.want || CodeOf(err) != spec.wantCode {
+			t.Errorf("PageSize(%d) = %d, %v, want %d and code %v", spec.size, got, err, spec.want, spec.wantCode)
+		}
+	}
+}
+
+func TestSetNextPage(t *testing.T) {
+	o := NewServerOptions()
+	for _, spec := range []struct {
+		method string
+		token  string
+		want   string
+	}{
+		{method: http.MethodGet, token: "p2", want: `</v1/books?filter=new&page_size=2&page_token=p2>; rel="next"`},
+		{method: http.MethodGet},
+		{method: http.MethodPost, token: "p2"},
+	} {
+		w := httptest.NewRecorder()
+		o.SetNextPage(w, httptest.NewRequest(spec.method, "/v1/books?page_size=2&filter=new&page_token=p1", nil), spec.token)
+		if got := w.Header().Get("Link"); got != spec.want {
+			t.Errorf("SetNextPage() of a %s with the token %q set Link to %q, want %q", spec.method, spec.token, got, spec.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package runtime
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicHandler is called with the value p of a panic recovered while the method fullMethod,
+// e.g. "/library.v1.Library/GetBook", was serving r, and with the stack trace of the panicking goroutine.
+type PanicHandler func(r *http.Request, fullMethod string, p any, stack []byte)
+
+// WithPanicHandler sets the hook called with the panics recovered in the implementation.
+// By default they are logged with the standard logger, like net/http does.
+func WithPanicHandler(h PanicHandler) ServerOption {
+	return func(o *ServerOptions) {
+		o.panicHandler = h
+	}
+}
+
+// Recover recovers a panic of the implementation of fullMethod serving r, and sets *err to an internal error,
+// so that the handler writes a 500 in the error format of its protocol. The panic is reported to the PanicHandler.
+// It must be deferred directly around the call of the implementation.
+// http.ErrAbortHandler is panicked again, since it is meant to abort the response.
+func (o *ServerOptions) Recover(r *http.Request, fullMethod string, err *error) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	if p == http.ErrAbortHandler {
+		panic(p)
+	}
+	stack := debug.Stack()
+	if o.panicHandler != nil {
+		o.panicHandler(r, fullMethod, p, stack)
+	} else {
+		log.Printf("runtime: panic serving %s: %v\n%s", fullMethod, p, stack)
+	}
+	*err = NewError(CodeInternal, "internal error")
+}
@@ -0,0 +1,73 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestRecover(t *testing.T) {
+	var gotMethod string
+	var gotPanic any
+	var gotStack []byte
+	o := NewServerOptions(WithPanicHandler(func(r *http.Request, fullMethod string, p any, stack []byte) {
+		gotMethod, gotPanic, gotStack = fullMethod, p, stack
+	}))
+
+	// A REST handler, as generated.
+	r := httptest.NewRequest(http.MethodGet, "/v1/books/1", nil)
+	w := httptest.NewRecorder()
+	var err error
+	func() {
+		defer o.Recover(r, "/library.v1.Library/GetBook", &err)
+		panic("boom")
+	}()
+	if err != nil {
+		o.WriteError(r.Context(), w, err)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d; want %d", w.Code, http.StatusInternalServerError)
+	}
+	if got, want := strings.TrimSuffix(w.Body.String(), "\n"), `{"code":13,"message":"internal: internal error"}`; got != want {
+		t.Errorf("body = %q; want %q", got, want)
+	}
+	if gotMethod != "/library.v1.Library/GetBook" || gotPanic != "boom" {
+		t.Errorf("PanicHandler called with %q, %v; want /library.v1.Library/GetBook, boom", gotMethod, gotPanic)
+	}
+	if !strings.Contains(string(gotStack), "TestRecover") {
+		t.Errorf("stack does not contain the panicking function:\n%s", gotStack)
+	}
+
+	// A Connect handler, as generated.
+	r = httptest.NewRequest(http.MethodPost, "/library.v1.Library/GetBook", strings.NewReader(`"hi"`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	o.ServeConnect(w, r, false, &wrapperspb.StringValue{}, func(ctx context.Context) (out proto.Message, err error) {
+		defer o.Recover(r, "/library.v1.Library/GetBook", &err)
+		var m map[string]string
+		m["nil"] = "map"
+		return nil, nil
+	})
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d; want %d", w.Code, http.StatusInternalServerError)
+	}
+	if got, want := strings.TrimSuffix(w.Body.String(), "\n"), `{"code":"internal","message":"internal error"}`; got != want {
+		t.Errorf("body = %q; want %q", got, want)
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("recovered %v; want http.ErrAbortHandler", p)
+		}
+	}()
+	var err error
+	defer NewServerOptions().Recover(httptest.NewRequest(http.MethodGet, "/", nil), "/library.v1.Library/GetBook", &err)
+	panic(http.ErrAbortHandler)
+}
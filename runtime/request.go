@@ -0,0 +1,145 @@
+package runtime
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gorilla/schema"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	queryDecoder = schema.NewDecoder()
+)
+
+func init() {
+	queryDecoder.SetAliasTag("json")
+	queryDecoder.IgnoreUnknownKeys(true)
+}
+
+// DecodeQuery binds the URL query parameters of r to in.
+// Parameters are matched against the json names of the fields, nested fields are addressed as "parent.child".
+func (o *ServerOptions) DecodeQuery(r *http.Request, in proto.Message) error {
+	return queryDecoder.Decode(in, r.URL.Query())
+}
+
+// DecodeBody binds the request body of r to in according to its Content-Type.
+//
+// application/x-www-form-urlencoded and multipart/form-data bodies are bound with the same rules as query parameters.
+// File parts of a multipart body are stored into the top-level bytes or google.api.HttpBody field they are named after.
+// Any other body is decoded as JSON, an empty body leaves in untouched.
+// A body with a registered Content-Encoding, such as gzip, is decompressed first.
+func (o *ServerOptions) DecodeBody(r *http.Request, in proto.Message) error {
+	if err := o.requestBody(r); err != nil {
+		return err
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return err
+		}
+		return queryDecoder.Decode(in, r.PostForm)
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(o.maxMemory); err != nil {
+			return err
+		}
+		defer r.MultipartForm.RemoveAll()
+		if err := queryDecoder.Decode(in, r.MultipartForm.Value); err != nil {
+			return err
+		}
+		return decodeFiles(in, r.MultipartForm.File)
+	}
+
+	reqba, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(reqba, in)
+}
+
+// decodeJSON decodes the JSON body data into in, an empty body leaves in untouched.
+func decodeJSON(data []byte, in proto.Message) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, in)
+}
+
+// ReadHttpBody reads the raw request body and its content type into body.
+func (o *ServerOptions) ReadHttpBody(r *http.Request, body *httpbody.HttpBody) error {
+	if err := o.requestBody(r); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	body.ContentType = r.Header.Get("Content-Type")
+	body.Data = data
+	return nil
+}
+
+func (o *ServerOptions) limitBody(r *http.Request) {
+	if o.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, o.maxBodySize)
+	}
+}
+
+// decodeFiles stores the uploaded files into the fields of in named after their form keys.
+// Files whose key names no bytes or google.api.HttpBody field are ignored, like unknown form values.
+func decodeFiles(in proto.Message, files map[string][]*multipart.FileHeader) error {
+	m := in.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for key, fhs := range files {
+		fd := fields.ByJSONName(key)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(key))
+		}
+		if fd == nil || len(fhs) == 0 {
+			continue
+		}
+		switch {
+		case fd.Kind() == protoreflect.BytesKind && fd.IsList():
+			list := m.Mutable(fd).List()
+			for _, fh := range fhs {
+				data, err := readFile(fh)
+				if err != nil {
+					return err
+				}
+				list.Append(protoreflect.ValueOfBytes(data))
+			}
+		case fd.Kind() == protoreflect.BytesKind:
+			data, err := readFile(fhs[0])
+			if err != nil {
+				return err
+			}
+			m.Set(fd, protoreflect.ValueOfBytes(data))
+		case fd.Message() != nil && fd.Message().FullName() == "google.api.HttpBody" && !fd.IsList():
+			data, err := readFile(fhs[0])
+			if err != nil {
+				return err
+			}
+			body := m.NewField(fd).Message()
+			bodyFields := body.Descriptor().Fields()
+			body.Set(bodyFields.ByName("content_type"), protoreflect.ValueOfString(fhs[0].Header.Get("Content-Type")))
+			body.Set(bodyFields.ByName("data"), protoreflect.ValueOfBytes(data))
+			m.Set(fd, protoreflect.ValueOfMessage(body))
+		}
+	}
+	return nil
+}
+
+func readFile(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -0,0 +1,73 @@
+package runtime
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+)
+
+func TestDecodeBody(t *testing.T) {
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	mw.WriteField("content_type", "image/png")
+	hdr := textproto.MIMEHeader{}
+	hdr.Set("Content-Disposition", `form-data; name="data"; filename="icon.png"`)
+	hdr.Set("Content-Type", "image/png")
+	part, _ := mw.CreatePart(hdr)
+	part.Write([]byte("\x89PNG"))
+	mw.Close()
+
+	for _, spec := range []struct {
+		contentType string
+		body        string
+		want        *httpbody.HttpBody
+	}{
+		{
+			contentType: "application/json",
+			body:        `{"content_type":"text/plain","data":"aGk="}`,
+			want:        &httpbody.HttpBody{ContentType: "text/plain", Data: []byte("hi")},
+		},
+		{
+			contentType: "",
+			body:        "",
+			want:        &httpbody.HttpBody{},
+		},
+		{
+			contentType: "application/x-www-form-urlencoded",
+			body:        "content_type=text%2Fplain&unknown=1",
+			want:        &httpbody.HttpBody{ContentType: "text/plain"},
+		},
+		{
+			contentType: mw.FormDataContentType(),
+			body:        multipartBody.String(),
+			want:        &httpbody.HttpBody{ContentType: "image/png", Data: []byte("\x89PNG")},
+		},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(spec.body))
+		if spec.contentType != "" {
+			r.Header.Set("Content-Type", spec.contentType)
+		}
+		got := &httpbody.HttpBody{}
+		if err := NewServerOptions().DecodeBody(r, got); err != nil {
+			t.Errorf("DecodeBody(%q) failed with %v; want success", spec.contentType, err)
+			continue
+		}
+		if got.GetContentType() != spec.want.GetContentType() || !bytes.Equal(got.GetData(), spec.want.GetData()) {
+			t.Errorf("DecodeBody(%q) = %v; want %v", spec.contentType, got, spec.want)
+		}
+	}
+}
+
+func TestDecodeBodyTooLarge(t *testing.T) {
+	o := NewServerOptions(WithMaxBodySize(4))
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"content_type":"text/plain"}`))
+	if err := o.DecodeBody(r, &httpbody.HttpBody{}); err == nil {
+		t.Errorf("DecodeBody() of a body over the limit succeeded; want error")
+	}
+}
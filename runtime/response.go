@@ -0,0 +1,103 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+)
+
+// WriteError writes err as the JSON error body, with its message and its code, along with the response metadata
+// set through ctx. The code is the one of the Code() int method of err, or else the number of CodeOf(err).
+// The status is the HTTPStatus of CodeOf(err), or 400 when the code is unknown,
+// but 412 for the failed preconditions and aborted calls of requests with an If-Match header under WithETags.
+func (o *ServerOptions) WriteError(ctx context.Context, w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	if code := CodeOf(err); code != CodeUnknown {
+		status = code.HTTPStatus()
+	}
+	if preconditionFailed(ctx, err) {
+		status = http.StatusPreconditionFailed
+	}
+	setCallError(ctx, err)
+	writeHeader(ctx, w)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	errRst := map[string]any{}
+	errRst["message"] = err.Error()
+	errRst["code"] = CodeOf(err)
+	if cerr, ok := err.(interface{ Code() int }); ok {
+		errRst["code"] = cerr.Code()
+	}
+	jenc := json.NewEncoder(w)
+	jenc.SetEscapeHTML(false)
+	jenc.Encode(errRst)
+	writeTrailer(ctx, w)
+}
+
+// WriteResponse writes resp as the JSON response body, along with the response metadata set through ctx.
+// code is the status declared for the method; the implementation may override it with SetStatus.
+// Statuses that forbid a body, such as 204, are written without one.
+// Bodies of at least the WithCompressMinSize size are compressed with the coding accepted by the client.
+// Under WithETags, GET responses get an ETag and are answered with 304 when it matches If-None-Match.
+// Under WithPartialResponses, resp is pruned to the fields asked for by the request.
+func (o *ServerOptions) WriteResponse(ctx context.Context, w http.ResponseWriter, code int, resp any) {
+	resp, err := partialResponse(ctx, resp)
+	if err != nil {
+		o.WriteError(ctx, w, err)
+		return
+	}
+	code = statusFromContext(ctx, code)
+	writeHeader(ctx, w)
+	if !bodyAllowed(code) {
+		w.WriteHeader(code)
+		writeTrailer(ctx, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	var buf bytes.Buffer
+	jenc := json.NewEncoder(&buf)
+	jenc.SetEscapeHTML(false)
+	jenc.Encode(resp)
+	if o.notModified(ctx, w, code, resp, buf.Bytes()) {
+		writeTrailer(ctx, w)
+		return
+	}
+	o.writeBody(ctx, w, code, buf.Bytes())
+	writeTrailer(ctx, w)
+}
+
+// bodyAllowed reports whether a response with the status code may carry a body, see RFC 9110.
+func bodyAllowed(code int) bool {
+	switch {
+	case code >= 100 && code <= 199:
+		return false
+	case code == http.StatusNoContent, code == http.StatusResetContent, code == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// WriteHttpBody writes the data of body as the raw response body with its content type.
+func (o *ServerOptions) WriteHttpBody(ctx context.Context, w http.ResponseWriter, code int, body *httpbody.HttpBody) {
+	code = statusFromContext(ctx, code)
+	writeHeader(ctx, w)
+	if !bodyAllowed(code) {
+		w.WriteHeader(code)
+		writeTrailer(ctx, w)
+		return
+	}
+	contentType := body.GetContentType()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if o.notModified(ctx, w, code, nil, body.GetData()) {
+		writeTrailer(ctx, w)
+		return
+	}
+	o.writeBody(ctx, w, code, body.GetData())
+	writeTrailer(ctx, w)
+}
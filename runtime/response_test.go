@@ -0,0 +1,86 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHttpBody(t *testing.T) {
+	o := NewServerOptions()
+	r := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader([]byte("\x89PNG")))
+	r.Header.Set("Content-Type", "image/png")
+
+	in := &httpbody.HttpBody{}
+	if err := o.ReadHttpBody(r, in); err != nil {
+		t.Fatalf("ReadHttpBody() failed with %v", err)
+	}
+	if got, want := in.GetContentType(), "image/png"; got != want {
+		t.Errorf("ReadHttpBody() content type = %q; want %q", got, want)
+	}
+	if got, want := string(in.GetData()), "\x89PNG"; got != want {
+		t.Errorf("ReadHttpBody() data = %q; want %q", got, want)
+	}
+
+	for _, spec := range []struct {
+		body        *httpbody.HttpBody
+		contentType string
+	}{
+		{
+			body:        &httpbody.HttpBody{ContentType: "text/csv", Data: []byte("a,b\n")},
+			contentType: "text/csv",
+		},
+		{
+			body:        &httpbody.HttpBody{Data: []byte{0, 1}},
+			contentType: "application/octet-stream",
+		},
+	} {
+		rec := httptest.NewRecorder()
+		o.WriteHttpBody(o.NewContext(r), rec, http.StatusOK, spec.body)
+		if got, want := rec.Header().Get("Content-Type"), spec.contentType; got != want {
+			t.Errorf("WriteHttpBody() content type = %q; want %q", got, want)
+		}
+		if got, want := rec.Body.Bytes(), spec.body.GetData(); !bytes.Equal(got, want) {
+			t.Errorf("WriteHttpBody() body = %q; want %q", got, want)
+		}
+	}
+}
+
+// codeError is an application error with its own code.
+type codeError struct{}
+
+func (codeError) Error() string { return "quota" }
+func (codeError) Code() int     { return 1001 }
+
+func TestWriteError(t *testing.T) {
+	o := NewServerOptions()
+	for _, spec := range []struct {
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{err: NewError(CodeNotFound, "no book 1"), wantStatus: http.StatusNotFound, wantBody: `{"code":5,"message":"not_found: no book 1"}`},
+		{err: context.DeadlineExceeded, wantStatus: http.StatusGatewayTimeout, wantBody: `{"code":4,"message":"context deadline exceeded"}`},
+		{err: codeError{}, wantStatus: http.StatusBadRequest, wantBody: `{"code":1001,"message":"quota"}`},
+		{err: status.Error(codes.NotFound, "no book 2"), wantStatus: http.StatusNotFound, wantBody: `{"code":5,"message":"rpc error: code = NotFound desc = no book 2"}`},
+		{err: errors.New("bad book"), wantStatus: http.StatusBadRequest, wantBody: `{"code":2,"message":"bad book"}`},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/v1/books/1", nil)
+		w := httptest.NewRecorder()
+		o.WriteError(o.NewContext(r), w, spec.err)
+		if w.Code != spec.wantStatus {
+			t.Errorf("WriteError(%v) status = %d; want %d", spec.err, w.Code, spec.wantStatus)
+		}
+		if got := strings.TrimSuffix(w.Body.String(), "\n"); got != spec.wantBody {
+			t.Errorf("WriteError(%v) body = %q; want %q", spec.err, got, spec.wantBody)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package runtime
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The protocols of the routes.
+const (
+	// ProtocolREST is the protocol of the google.api.http rules and of the default routes of the methods without one.
+	ProtocolREST = "rest"
+	// ProtocolConnect is the Connect unary protocol.
+	ProtocolConnect = "connect"
+	// ProtocolTwirp is the Twirp protocol.
+	ProtocolTwirp = "twirp"
+	// ProtocolGRPCWeb is the gRPC-Web protocol.
+	ProtocolGRPCWeb = "grpc-web"
+)
+
+// Route describes a route registered by the generated handlers,
+// as returned by the generated XxxHTTPRoutes functions.
+type Route struct {
+	// Protocol is the protocol the route serves the method with, e.g. ProtocolREST.
+	Protocol string
+	// HTTPMethod is the HTTP method of the route, e.g. "GET". It is empty for the Connect routes,
+	// which answer POST, and GET for the methods without side effects.
+	HTTPMethod string
+	// Template is the path template of the route as written in the google.api.http rule, e.g. "/v1/{name=books/*}".
+	Template string
+	// Pattern is the http.ServeMux pattern the handler is registered with. The gRPC-Web route of a method
+	// whose gRPC-Web requests are served by the handler of another route has the pattern of that route.
+	Pattern string
+	// FullMethod is the gRPC full method name, e.g. "/library.v1.Library/GetBook".
+	FullMethod string
+	// Input and Output describe the request and response messages of the method.
+	Input  protoreflect.MessageDescriptor
+	Output protoreflect.MessageDescriptor
+	// Body and ResponseBody are the body and response_body field paths of the google.api.http rule.
+	Body         string
+	ResponseBody string
+	// Deprecated reports whether the method is deprecated.
+	Deprecated bool
+}
@@ -0,0 +1,37 @@
+package runtime
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// testMessages returns the descriptors of the messages of schema, a FileDescriptorProto as text.
+// Its dependencies are resolved among the files linked in the test binary.
+func testMessages(t *testing.T, schema string) protoreflect.MessageDescriptors {
+	t.Helper()
+	fdp := &descriptorpb.FileDescriptorProto{}
+	if err := prototext.Unmarshal([]byte(schema), fdp); err != nil {
+		t.Fatalf("prototext.Unmarshal() failed with %v", err)
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile() failed with %v", err)
+	}
+	return fd.Messages()
+}
+
+// newTestMessage returns a message of type md set from text, the message as text.
+func newTestMessage(t *testing.T, md protoreflect.MessageDescriptor, text string) *dynamicpb.Message {
+	t.Helper()
+	m := dynamicpb.NewMessage(md)
+	if err := prototext.Unmarshal([]byte(text), m); err != nil {
+		t.Fatalf("prototext.Unmarshal() failed with %v", err)
+	}
+	return m
+}
@@ -0,0 +1,129 @@
+// Package runtime contains the helpers shared by the handlers generated by protoc-gen-http-go.
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultIncomingHeaders is the header allowlist used when WithIncomingHeaders is not given.
+var DefaultIncomingHeaders = []string{"Authorization", "X-Request-Id"}
+
+// DefaultMaxMemory is the part of a multipart/form-data body kept in memory when WithMaxMemory is not given,
+// the remainder is stored on disk in temporary files.
+const DefaultMaxMemory = 32 << 20
+
+// ServerOptions holds the settings of generated handlers.
+type ServerOptions struct {
+	incomingHeaders  []string
+	maxMemory        int64
+	maxBodySize      int64
+	compressMinSize  int
+	etags            bool
+	partialResponses bool
+	maxPageSize      int32
+	openAPIPath      string
+	explorerPath     string
+	cors             *CORS
+	timeout          time.Duration
+	panicHandler     PanicHandler
+	tracerProvider   trace.TracerProvider
+	statsHandlers    []StatsHandler
+}
+
+// ServerOption configures generated handlers.
+type ServerOption func(*ServerOptions)
+
+// WithIncomingHeaders sets the request headers copied into the incoming metadata.
+// An entry ending with "*" matches every header with that prefix, so "*" forwards all headers.
+func WithIncomingHeaders(keys ...string) ServerOption {
+	return func(o *ServerOptions) {
+		o.incomingHeaders = keys
+	}
+}
+
+// WithMaxMemory sets how many bytes of a multipart/form-data body are kept in memory while parsing it.
+func WithMaxMemory(n int64) ServerOption {
+	return func(o *ServerOptions) {
+		o.maxMemory = n
+	}
+}
+
+// WithMaxBodySize limits the size of request bodies, larger requests fail to decode.
+// A value <= 0, the default, means no limit.
+func WithMaxBodySize(n int64) ServerOption {
+	return func(o *ServerOptions) {
+		o.maxBodySize = n
+	}
+}
+
+// WithOpenAPI makes RegisterHttpServer serve the OpenAPI document of the service as JSON at path, e.g. "/openapi.json".
+// The document is only available when the handlers are generated with the serve_openapi option.
+func WithOpenAPI(path string) ServerOption {
+	return func(o *ServerOptions) {
+		o.openAPIPath = path
+	}
+}
+
+// WithExplorer makes RegisterHttpServer serve at path a self-contained HTML page to browse and call the API.
+// The page reads the document served through WithOpenAPI.
+func WithExplorer(path string) ServerOption {
+	return func(o *ServerOptions) {
+		o.explorerPath = path
+	}
+}
+
+// NewServerOptions applies opts over the defaults.
+func NewServerOptions(opts ...ServerOption) *ServerOptions {
+	o := &ServerOptions{
+		incomingHeaders: DefaultIncomingHeaders,
+		maxMemory:       DefaultMaxMemory,
+		compressMinSize: DefaultCompressMinSize,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	keys := make([]string, len(o.incomingHeaders))
+	for i, k := range o.incomingHeaders {
+		keys[i] = strings.ToLower(k)
+	}
+	o.incomingHeaders = keys
+	return o
+}
+
+// NewContext returns the context passed to the implementation for r.
+// It carries the allowed request headers as incoming metadata and collects the metadata given to SetHeader and SetTrailer.
+// It expires after the timeout asked for by the grpc-timeout, Connect-Timeout-Ms or X-Request-Timeout header of r,
+// capped by the timeout of the method, and the response compression negotiated with its Accept-Encoding header.
+func (o *ServerOptions) NewContext(r *http.Request) context.Context {
+	md := MD{}
+	for k, vs := range r.Header {
+		if o.allowHeader(strings.ToLower(k)) {
+			md.Append(k, vs...)
+		}
+	}
+	ctx := NewIncomingContext(o.withDeadline(r.Context(), r), md)
+	ctx = o.withCompressor(ctx, r)
+	ctx = o.withConditional(ctx, r)
+	ctx = o.withFields(ctx, r)
+	return newServerStreamContext(ctx)
+}
+
+func (o *ServerOptions) allowHeader(k string) bool {
+	for _, h := range o.incomingHeaders {
+		if prefix, ok := strings.CutSuffix(h, "*"); ok {
+			if strings.HasPrefix(k, prefix) {
+				return true
+			}
+			continue
+		}
+		if h == k {
+			return true
+		}
+	}
+	return false
+}
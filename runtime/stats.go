@@ -0,0 +1,57 @@
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// StatsHandler is notified of the stages of the calls served by the generated handlers, like grpc's stats.Handler.
+// Its methods are called on the goroutine serving the call and must be safe for concurrent calls.
+type StatsHandler interface {
+	// Begin is called when a call starts, before its request is read.
+	// The returned context, derived from ctx, is the one of the call and is passed to the other methods.
+	Begin(ctx context.Context, info *CallInfo) context.Context
+	// Decoded is called with the request message once it is decoded, right before the implementation runs.
+	// It is not called for requests failing to decode. req must not be modified.
+	Decoded(ctx context.Context, info *CallInfo, req proto.Message)
+	// End is called once the response is written.
+	End(ctx context.Context, info *CallInfo, stats *CallStats)
+}
+
+// CallInfo describes the method a call is served by.
+type CallInfo struct {
+	// FullMethod is the gRPC full method name, e.g. "/library.v1.Library/GetBook".
+	FullMethod string
+	// Service and Method are the two parts of FullMethod, e.g. "library.v1.Library" and "GetBook".
+	Service string
+	Method  string
+	// Route is the ServeMux path the handler is registered with, e.g. "/v1/books/{id}".
+	Route string
+	// HTTPMethod is the method of the request.
+	HTTPMethod string
+}
+
+// CallStats is the outcome of a call.
+type CallStats struct {
+	// Status is the HTTP status of the response.
+	Status int
+	// Code is the code of Err, CodeOK when the call succeeded.
+	// gRPC-Web calls answer errors with a 200 status, so Code is the one to tell failures with.
+	Code Code
+	// Err is the error the call answered with, if any.
+	Err error
+	// RequestBytes and ResponseBytes are the sizes of the request and response bodies as read and written.
+	RequestBytes  int64
+	ResponseBytes int64
+	// Duration is the time from Begin to End.
+	Duration time.Duration
+}
+
+// WithStatsHandler adds h to the handlers notified of every call. It may be given several times.
+func WithStatsHandler(h StatsHandler) ServerOption {
+	return func(o *ServerOptions) {
+		o.statsHandlers = append(o.statsHandlers, h)
+	}
+}
@@ -0,0 +1,154 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// recordingStats records the stages it is notified of.
+type recordingStats struct {
+	stages []string
+	stats  *CallStats
+}
+
+type stageKey struct{}
+
+func (s *recordingStats) Begin(ctx context.Context, info *CallInfo) context.Context {
+	s.stages = append(s.stages, "begin "+info.FullMethod+" "+info.Route+" "+info.HTTPMethod)
+	return context.WithValue(ctx, stageKey{}, "tagged")
+}
+
+func (s *recordingStats) Decoded(ctx context.Context, info *CallInfo, req proto.Message) {
+	s.stages = append(s.stages, "decoded "+ctx.Value(stageKey{}).(string))
+}
+
+func (s *recordingStats) End(ctx context.Context, info *CallInfo, stats *CallStats) {
+	s.stages = append(s.stages, "end")
+	s.stats = stats
+}
+
+func TestStatsHandler(t *testing.T) {
+	for _, spec := range []struct {
+		name       string
+		body       string
+		err        error
+		wantStages []string
+		wantStatus int
+		wantCode   Code
+	}{
+		{
+			name: "ok",
+			body: `{"value":"Dune"}`,
+			wantStages: []string{
+				"begin /library.v1.Library/CreateBook /v1/books POST",
+				"decoded tagged",
+				"end",
+			},
+			wantStatus: http.StatusOK,
+			wantCode:   CodeOK,
+		},
+		{
+			name: "error",
+			body: `{"value":"Dune"}`,
+			err:  NewError(CodeAlreadyExists, "exists"),
+			wantStages: []string{
+				"begin /library.v1.Library/CreateBook /v1/books POST",
+				"decoded tagged",
+				"end",
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   CodeAlreadyExists,
+		},
+		{
+			name: "decode error",
+			body: `{`,
+			wantStages: []string{
+				"begin /library.v1.Library/CreateBook /v1/books POST",
+				"end",
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeInvalidArgument,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			stats := &recordingStats{}
+			o := NewServerOptions(WithStatsHandler(stats))
+			h := o.Instrument("/library.v1.Library/CreateBook", "/v1/books", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := o.NewContext(r)
+				in := &wrapperspb.StringValue{}
+				if err := o.DecodeBody(r, in); err != nil {
+					o.WriteError(ctx, w, NewError(CodeInvalidArgument, err.Error()))
+					return
+				}
+				o.Decoded(ctx, in)
+				if spec.err != nil {
+					o.WriteError(ctx, w, spec.err)
+					return
+				}
+				o.WriteResponse(ctx, w, http.StatusOK, in)
+			}))
+			r := httptest.NewRequest(http.MethodPost, "/v1/books", strings.NewReader(spec.body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if !reflect.DeepEqual(stats.stages, spec.wantStages) {
+				t.Errorf("stages = %q, want %q", stats.stages, spec.wantStages)
+			}
+			if stats.stats.Status != spec.wantStatus || stats.stats.Code != spec.wantCode {
+				t.Errorf("stats status %d and code %v, want %d and %v", stats.stats.Status, stats.stats.Code, spec.wantStatus, spec.wantCode)
+			}
+			if got, want := stats.stats.RequestBytes, int64(len(spec.body)); got != want {
+				t.Errorf("stats request bytes = %d, want %d", got, want)
+			}
+			if got, want := stats.stats.ResponseBytes, int64(w.Body.Len()); got != want {
+				t.Errorf("stats response bytes = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(0.1, 1)
+	info := &CallInfo{FullMethod: "/library.v1.Library/GetBook", Service: "library.v1.Library", Method: "GetBook"}
+	for _, stats := range []*CallStats{
+		{Status: http.StatusOK, Code: CodeOK, RequestBytes: 0, ResponseBytes: 20, Duration: 50 * time.Millisecond},
+		{Status: http.StatusOK, Code: CodeOK, RequestBytes: 0, ResponseBytes: 22, Duration: 500 * time.Millisecond},
+		{Status: http.StatusNotFound, Code: CodeNotFound, RequestBytes: 0, ResponseBytes: 30, Duration: 2 * time.Second},
+	} {
+		ctx := m.Begin(context.Background(), info)
+		m.End(ctx, info, stats)
+	}
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", got)
+	}
+	const labels = `rpc_service="library.v1.Library",rpc_method="GetBook"`
+	for _, want := range []string{
+		"# TYPE http_go_server_started_total counter\n",
+		"http_go_server_started_total{" + labels + "} 3\n",
+		"http_go_server_handled_total{" + labels + `,code="ok",http_status="200"} 2` + "\n",
+		"http_go_server_handled_total{" + labels + `,code="not_found",http_status="404"} 1` + "\n",
+		"# TYPE http_go_server_handling_seconds histogram\n",
+		"http_go_server_handling_seconds_bucket{" + labels + `,le="0.1"} 1` + "\n",
+		"http_go_server_handling_seconds_bucket{" + labels + `,le="1"} 2` + "\n",
+		"http_go_server_handling_seconds_bucket{" + labels + `,le="+Inf"} 3` + "\n",
+		"http_go_server_handling_seconds_sum{" + labels + "} 2.55\n",
+		"http_go_server_handling_seconds_count{" + labels + "} 3\n",
+		"http_go_server_response_bytes_total{" + labels + "} 72\n",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, w.Body.String())
+		}
+	}
+}
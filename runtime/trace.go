@@ -0,0 +1,83 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of the generated handlers.
+const tracerName = "github.com/peterchanxyz/protoc-gen-http-go/runtime"
+
+// traceContext propagates the W3C traceparent and tracestate headers.
+var traceContext = propagation.TraceContext{}
+
+// WithTracing makes the generated handlers trace every call with a server span of tp named after the RPC,
+// e.g. "library.v1.Library/GetBook". The span continues the trace of the traceparent and tracestate headers
+// of the request and its context is passed to the implementation.
+func WithTracing(tp trace.TracerProvider) ServerOption {
+	return func(o *ServerOptions) {
+		o.tracerProvider = tp
+	}
+}
+
+// InjectTraceContext sets the traceparent and tracestate headers of the span of ctx in h,
+// for clients calling generated handlers to continue their trace.
+func InjectTraceContext(ctx context.Context, h http.Header) {
+	traceContext.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// NewTraceTransport returns an http.RoundTripper sending the requests with base, or http.DefaultTransport when nil,
+// with the traceparent and tracestate headers of the span of their context. The plugin generates no client:
+// the http.Client of the clients calling generated handlers, such as Connect or Twirp clients, uses it to
+// continue their trace.
+func NewTraceTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return traceTransport{base: base}
+}
+
+type traceTransport struct {
+	base http.RoundTripper
+}
+
+func (t traceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it is given.
+	r = r.Clone(r.Context())
+	InjectTraceContext(r.Context(), r.Header)
+	return t.base.RoundTrip(r)
+}
+
+// startSpan starts the server span of c serving r. end ends it with the HTTP status and error the call answered with.
+func (o *ServerOptions) startSpan(ctx context.Context, r *http.Request, c *call) (_ context.Context, end func(status int, err error)) {
+	ctx = traceContext.Extract(ctx, propagation.HeaderCarrier(r.Header))
+	ctx, span := o.tracerProvider.Tracer(tracerName).Start(ctx, c.info.Service+"/"+c.info.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.service", c.info.Service),
+			attribute.String("rpc.method", c.info.Method),
+			attribute.String("http.route", c.info.Route),
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		),
+	)
+	return ctx, func(status int, err error) {
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if err != nil {
+			code := CodeOf(err)
+			span.SetAttributes(attribute.String("error.type", code.String()))
+			if code.HTTPStatus() >= 500 {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, messageOf(err))
+			}
+		} else if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,183 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	for _, spec := range []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+		wantAttrs  map[attribute.Key]attribute.Value
+	}{
+		{
+			name:       "ok",
+			wantStatus: codes.Unset,
+			wantAttrs: map[attribute.Key]attribute.Value{
+				"rpc.service":               attribute.StringValue("library.v1.Library"),
+				"rpc.method":                attribute.StringValue("GetBook"),
+				"http.route":                attribute.StringValue("/v1/books/{id}"),
+				"http.request.method":       attribute.StringValue("GET"),
+				"http.response.status_code": attribute.IntValue(200),
+			},
+		},
+		{
+			name:       "client error",
+			err:        NewError(CodeNotFound, "no such book"),
+			wantStatus: codes.Unset,
+			wantAttrs: map[attribute.Key]attribute.Value{
+				"http.response.status_code": attribute.IntValue(404),
+				"error.type":                attribute.StringValue("not_found"),
+			},
+		},
+		{
+			name:       "server error",
+			err:        NewError(CodeUnavailable, "try later"),
+			wantStatus: codes.Error,
+			wantAttrs: map[attribute.Key]attribute.Value{
+				"http.response.status_code": attribute.IntValue(503),
+				"error.type":                attribute.StringValue("unavailable"),
+			},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			o := NewServerOptions(WithTracing(tp))
+			var implSpan trace.SpanContext
+			h := o.Instrument("/library.v1.Library/GetBook", "/v1/books/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := o.NewContext(r)
+				implSpan = trace.SpanContextFromContext(ctx)
+				if spec.err != nil {
+					o.WriteError(ctx, w, spec.err)
+					return
+				}
+				o.WriteResponse(ctx, w, http.StatusOK, map[string]string{"id": r.PathValue("id")})
+			}))
+			mux := http.NewServeMux()
+			mux.Handle("GET /v1/books/{id}", h)
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/books/1", nil)
+			r.Header.Set("Traceparent", traceparent)
+			mux.ServeHTTP(httptest.NewRecorder(), r)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("exported %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "library.v1.Library/GetBook" || span.SpanKind != trace.SpanKindServer {
+				t.Errorf("span %q of kind %v, want a server span library.v1.Library/GetBook", span.Name, span.SpanKind)
+			}
+			if got := span.Parent.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" || !span.Parent.IsRemote() {
+				t.Errorf("span parent is %v, want the remote span of the traceparent header", span.Parent)
+			}
+			if implSpan.SpanID() != span.SpanContext.SpanID() {
+				t.Errorf("implementation context carries span %v, want %v", implSpan.SpanID(), span.SpanContext.SpanID())
+			}
+			if span.Status.Code != spec.wantStatus {
+				t.Errorf("span status = %v, want %v", span.Status.Code, spec.wantStatus)
+			}
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			for k, want := range spec.wantAttrs {
+				if got := attrs[k]; got != want {
+					t.Errorf("attribute %s = %v, want %v", k, got.Emit(), want.Emit())
+				}
+			}
+		})
+	}
+}
+
+func TestTracingGRPCWebStatus(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	o := NewServerOptions(WithTracing(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))))
+	h := o.Instrument("/echo.v1.Echo/Echo", "/echo.v1.Echo/Echo", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o.ServeGRPCWeb(w, r, nil, func(stream *Stream) error { return nil })
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/echo.v1.Echo/Echo", nil)
+	r.Header.Set("Content-Type", "application/grpc-web")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	// The request has no message frame: the HTTP status is 200 and the error is in the trailer frame.
+	var errorType string
+	for _, kv := range spans[0].Attributes {
+		if kv.Key == "error.type" {
+			errorType = kv.Value.AsString()
+		}
+	}
+	if errorType != "invalid_argument" {
+		t.Errorf("error.type = %q, want invalid_argument", errorType)
+	}
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	h := http.Header{}
+	InjectTraceContext(trace.ContextWithSpanContext(context.Background(), sc), h)
+	if got, want := h.Get("Traceparent"), "00-01000000000000000000000000000000-0200000000000000-01"; got != want {
+		t.Errorf("traceparent = %q, want %q", got, want)
+	}
+}
+
+func TestTraceTransport(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	o := NewServerOptions(WithTracing(tp))
+	var traceparent string
+	mux := http.NewServeMux()
+	mux.Handle("GET /v1/books/{id}", o.Instrument("/library.v1.Library/GetBook", "/v1/books/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		o.WriteResponse(o.NewContext(r), w, http.StatusOK, map[string]string{"id": r.PathValue("id")})
+	})))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, client := tp.Tracer("client").Start(context.Background(), "GetBook", trace.WithSpanKind(trace.SpanKindClient))
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/books/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: NewTraceTransport(nil)}).Do(r)
+	if err != nil {
+		t.Fatalf("Do() failed with %v", err)
+	}
+	resp.Body.Close()
+	client.End()
+
+	if r.Header.Get("Traceparent") != "" {
+		t.Errorf("the transport modified the request headers")
+	}
+	if traceparent == "" {
+		t.Fatalf("the handler received no traceparent header")
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want the server and client spans", len(spans))
+	}
+	server := spans[0]
+	if server.SpanKind != trace.SpanKindServer || server.Parent.SpanID() != client.SpanContext().SpanID() || !server.Parent.IsRemote() {
+		t.Errorf("server span %q has the parent %v, want the remote client span %v", server.Name, server.Parent.SpanID(), client.SpanContext().SpanID())
+	}
+}
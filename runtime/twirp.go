@@ -0,0 +1,140 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ServeTwirp serves r with the Twirp protocol, see https://twitchtv.github.io/twirp/docs/spec_v7.html.
+//
+// The request is a POST whose body, in application/json or application/protobuf, is decoded into in.
+// call then runs the method with the context of the request. The response is encoded like the request
+// and errors are written as Twirp error JSON, with the status Twirp assigns to their code.
+func (o *ServerOptions) ServeTwirp(w http.ResponseWriter, r *http.Request, in proto.Message, call func(ctx context.Context) (proto.Message, error)) {
+	ctx := o.NewContext(r)
+	if r.Method != http.MethodPost {
+		writeTwirpProtocolError(ctx, w, "bad_route", http.StatusNotFound, CodeUnimplemented, "unsupported method "+r.Method+" (only POST is allowed)")
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" && mediaType != "application/protobuf" {
+		writeTwirpProtocolError(ctx, w, "bad_route", http.StatusNotFound, CodeUnimplemented, "unexpected Content-Type: "+r.Header.Get("Content-Type"))
+		return
+	}
+
+	if err := o.requestBody(r); err != nil {
+		o.writeTwirpError(ctx, w, err)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		o.writeTwirpError(ctx, w, Errorf(CodeOf(err), "failed to read request body: %w", err))
+		return
+	}
+	if mediaType == "application/protobuf" {
+		err = proto.Unmarshal(data, in)
+	} else {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, in)
+	}
+	if err != nil {
+		writeTwirpProtocolError(ctx, w, "malformed", http.StatusBadRequest, CodeInvalidArgument, "the request could not be decoded: "+err.Error())
+		return
+	}
+	o.Decoded(ctx, in)
+
+	out, err := call(ctx)
+	if err != nil {
+		o.writeTwirpError(ctx, w, err)
+		return
+	}
+	if mediaType == "application/protobuf" {
+		data, err = proto.Marshal(out)
+	} else {
+		data, err = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(out)
+	}
+	if err != nil {
+		o.writeTwirpError(ctx, w, Errorf(CodeInternal, "failed to marshal response: %v", err))
+		return
+	}
+	writeHeader(ctx, w)
+	w.Header().Set("Content-Type", mediaType)
+	o.writeBody(ctx, w, http.StatusOK, data)
+	writeTrailer(ctx, w)
+}
+
+// writeTwirpError writes err with the Twirp name and status of its Code.
+func (o *ServerOptions) writeTwirpError(ctx context.Context, w http.ResponseWriter, err error) {
+	setCallError(ctx, err)
+	code, status := twirpCode(CodeOf(err))
+	var meta map[string]string
+	var e *Error
+	if errors.As(err, &e) {
+		meta = e.meta
+	}
+	writeTwirpError(ctx, w, code, status, messageOf(err), meta)
+}
+
+// writeTwirpProtocolError writes the Twirp error name with status, for the bad_route and malformed errors,
+// which have no Code. The call records it as an error of code, like the errors of writeTwirpError.
+func writeTwirpProtocolError(ctx context.Context, w http.ResponseWriter, name string, status int, code Code, msg string) {
+	setCallError(ctx, NewError(code, msg))
+	writeTwirpError(ctx, w, name, status, msg, nil)
+}
+
+func writeTwirpError(ctx context.Context, w http.ResponseWriter, code string, status int, msg string, meta map[string]string) {
+	writeHeader(ctx, w)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	jenc := json.NewEncoder(w)
+	jenc.SetEscapeHTML(false)
+	jenc.Encode(struct {
+		Code string            `json:"code"`
+		Msg  string            `json:"msg"`
+		Meta map[string]string `json:"meta,omitempty"`
+	}{Code: code, Msg: msg, Meta: meta})
+	writeTrailer(ctx, w)
+}
+
+// twirpCode returns the Twirp name of code and the HTTP status of its errors.
+func twirpCode(code Code) (string, int) {
+	switch code {
+	case CodeCanceled:
+		return "canceled", http.StatusRequestTimeout
+	case CodeInvalidArgument:
+		return "invalid_argument", http.StatusBadRequest
+	case CodeDeadlineExceeded:
+		return "deadline_exceeded", http.StatusRequestTimeout
+	case CodeNotFound:
+		return "not_found", http.StatusNotFound
+	case CodeAlreadyExists:
+		return "already_exists", http.StatusConflict
+	case CodePermissionDenied:
+		return "permission_denied", http.StatusForbidden
+	case CodeResourceExhausted:
+		return "resource_exhausted", http.StatusTooManyRequests
+	case CodeFailedPrecondition:
+		return "failed_precondition", http.StatusPreconditionFailed
+	case CodeAborted:
+		return "aborted", http.StatusConflict
+	case CodeOutOfRange:
+		return "out_of_range", http.StatusBadRequest
+	case CodeUnimplemented:
+		return "unimplemented", http.StatusNotImplemented
+	case CodeInternal:
+		return "internal", http.StatusInternalServerError
+	case CodeUnavailable:
+		return "unavailable", http.StatusServiceUnavailable
+	case CodeDataLoss:
+		return "dataloss", http.StatusInternalServerError
+	case CodeUnauthenticated:
+		return "unauthenticated", http.StatusUnauthorized
+	}
+	return "unknown", http.StatusInternalServerError
+}
@@ -0,0 +1,104 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestServeTwirp(t *testing.T) {
+	binary, _ := proto.Marshal(wrapperspb.String("hi"))
+	for _, spec := range []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		err         error
+		wantStatus  int
+		wantType    string
+		wantBody    string
+		wantCode    Code
+	}{
+		{
+			name:        "json",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `"hi"`,
+			wantStatus:  http.StatusOK,
+			wantType:    "application/json",
+			wantBody:    `"hi"`,
+		},
+		{
+			name:        "protobuf",
+			method:      http.MethodPost,
+			contentType: "application/protobuf",
+			body:        string(binary),
+			wantStatus:  http.StatusOK,
+			wantType:    "application/protobuf",
+			wantBody:    string(binary),
+		},
+		{
+			name:       "bad route",
+			method:     http.MethodGet,
+			wantStatus: http.StatusNotFound,
+			wantType:   "application/json",
+			wantBody:   `{"code":"bad_route","msg":"unsupported method GET (only POST is allowed)"}`,
+			wantCode:   CodeUnimplemented,
+		},
+		{
+			name:        "malformed",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `{`,
+			wantStatus:  http.StatusBadRequest,
+			wantType:    "application/json",
+			wantCode:    CodeInvalidArgument,
+		},
+		{
+			name:        "error",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `"hi"`,
+			err:         NewError(CodeFailedPrecondition, "stale").WithMeta("version", "2"),
+			wantStatus:  http.StatusPreconditionFailed,
+			wantType:    "application/json",
+			wantBody:    `{"code":"failed_precondition","msg":"stale","meta":{"version":"2"}}`,
+			wantCode:    CodeFailedPrecondition,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			r := httptest.NewRequest(spec.method, "/twirp/echo.v1.Echo/Echo", strings.NewReader(spec.body))
+			r.Header.Set("Content-Type", spec.contentType)
+			w := httptest.NewRecorder()
+			in := &wrapperspb.StringValue{}
+			stats := &recordingStats{}
+			o := NewServerOptions(WithStatsHandler(stats))
+			o.Instrument("/echo.v1.Echo/Echo", "/twirp/echo.v1.Echo/Echo", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				o.ServeTwirp(w, r, in, func(ctx context.Context) (proto.Message, error) {
+					if spec.err != nil {
+						return nil, spec.err
+					}
+					return in, nil
+				})
+			})).ServeHTTP(w, r)
+			if w.Code != spec.wantStatus {
+				t.Fatalf("status = %d; want %d; body %q", w.Code, spec.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); got != spec.wantType {
+				t.Errorf("Content-Type = %q; want %q", got, spec.wantType)
+			}
+			if got := strings.TrimSuffix(w.Body.String(), "\n"); spec.wantBody != "" && got != spec.wantBody {
+				t.Errorf("body = %q; want %q", got, spec.wantBody)
+			}
+			// Every error is recorded, the ones of the Twirp protocol too.
+			if stats.stats.Code != spec.wantCode {
+				t.Errorf("stats code = %v; want %v", stats.stats.Code, spec.wantCode)
+			}
+		})
+	}
+}